@@ -0,0 +1,112 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+// Index 描述了节点 ID 索引，用于通过 ID 快速查找节点。
+//
+// 索引挂在根节点上，通过 InsertAfter、InsertBefore、AppendChild、PrependChild、Unlink、SetIALAttr 和 RemoveIALAttr
+// 修改树时索引会同步更新。
+type Index struct {
+	root  *Node            // 索引所属根节点
+	nodes map[string]*Node // ID 到节点的映射
+}
+
+// BuildIndex 为以 root 为根的树建立节点 ID 索引。如果已经建立过索引则直接返回。
+func BuildIndex(root *Node) *Index {
+	if nil != root.index {
+		return root.index
+	}
+
+	ret := &Index{root: root, nodes: map[string]*Node{}}
+	ret.add(root)
+	root.index = ret
+	return ret
+}
+
+// Get 返回 id 对应的节点，找不到时返回 nil。
+func (idx *Index) Get(id string) *Node {
+	return idx.nodes[id]
+}
+
+// Len 返回索引中的节点数量。
+func (idx *Index) Len() int {
+	return len(idx.nodes)
+}
+
+// Release 释放索引，释放后修改树时不再维护该索引。
+func (idx *Index) Release() {
+	if idx.root.index != idx {
+		return
+	}
+	idx.root.index = nil
+	idx.nodes = map[string]*Node{}
+}
+
+// add 将 n 及其子节点加入索引。
+func (idx *Index) add(n *Node) {
+	Walk(n, func(n *Node, entering bool) WalkStatus {
+		if entering {
+			if id := n.indexID(); "" != id {
+				idx.nodes[id] = n
+			}
+		}
+		return WalkContinue
+	})
+}
+
+// remove 将 n 及其子节点从索引中移除。
+func (idx *Index) remove(n *Node) {
+	Walk(n, func(n *Node, entering bool) WalkStatus {
+		if entering {
+			if id := n.indexID(); "" != id && n == idx.nodes[id] {
+				delete(idx.nodes, id)
+			}
+		}
+		return WalkContinue
+	})
+}
+
+// indexID 返回 n 在索引中使用的 ID，优先使用 IAL 中的 id 属性。
+func (n *Node) indexID() string {
+	if NodeKramdownBlockIAL == n.Type || NodeKramdownSpanIAL == n.Type {
+		return ""
+	}
+	if id := n.IALAttr("id"); "" != id {
+		return id
+	}
+	return n.ID
+}
+
+// treeIndex 返回 n 所在树的索引，没有建立索引时返回 nil。索引只保存在各自的根节点上，不同的树互不影响。
+func (n *Node) treeIndex() *Index {
+	root := n
+	for nil != root.Parent {
+		root = root.Parent
+	}
+	return root.index
+}
+
+// indexAdd 在 n 挂到树上以后将其加入索引。
+func (n *Node) indexAdd() {
+	if idx := n.treeIndex(); nil != idx {
+		idx.add(n)
+	}
+}
+
+// indexRemove 在 n 从树上移除之前将其移出索引。
+func (n *Node) indexRemove() {
+	if nil == n.Parent {
+		return
+	}
+	if idx := n.treeIndex(); nil != idx {
+		idx.remove(n)
+	}
+}
@@ -108,6 +108,12 @@ type Node struct {
 
 	// Kramdown 内联属性列表
 	KramdownIAL [][]string
+
+	// 源码位置
+
+	SourceLine int `json:",omitempty"` // 块级节点在 Markdown 源码中的起始行号（从 1 开始），0 表示未知
+
+	index *Index // 节点 ID 索引，仅在根节点上设置
 }
 
 // ListData 用于记录列表或列表项节点的附加信息。
@@ -149,6 +155,10 @@ func randStr(length int) string {
 }
 
 func (n *Node) RemoveIALAttr(name string) {
+	if "id" == name {
+		n.indexRemove()
+		defer n.indexAdd()
+	}
 	tmp := n.KramdownIAL[:0]
 	for _, kv := range n.KramdownIAL {
 		if name != kv[0] {
@@ -159,6 +169,10 @@ func (n *Node) RemoveIALAttr(name string) {
 }
 
 func (n *Node) SetIALAttr(name, value string) {
+	if "id" == name {
+		n.indexRemove()
+		defer n.indexAdd()
+	}
 	for _, kv := range n.KramdownIAL {
 		if name == kv[0] {
 			kv[1] = value
@@ -280,6 +294,7 @@ func (n *Node) PreviousNodeText() string {
 
 // Unlink 用于将节点从树上移除，后一个兄弟节点会接替该节点。
 func (n *Node) Unlink() {
+	n.indexRemove()
 	if nil != n.Previous {
 		n.Previous.Next = n.Next
 	} else if nil != n.Parent {
@@ -313,6 +328,7 @@ func (n *Node) InsertAfter(sibling *Node) {
 	if nil != sibling.Parent && nil == sibling.Next && nil != sibling.Parent.LastChild {
 		sibling.Parent.LastChild = sibling
 	}
+	sibling.indexAdd()
 }
 
 // InsertBefore 在当前节点前插入一个兄弟节点。
@@ -328,6 +344,7 @@ func (n *Node) InsertBefore(sibling *Node) {
 	if nil != sibling.Parent && nil == sibling.Previous {
 		sibling.Parent.FirstChild = sibling
 	}
+	sibling.indexAdd()
 }
 
 // AppendChild 在 n 的子节点最后再添加一个子节点。
@@ -342,6 +359,7 @@ func (n *Node) AppendChild(child *Node) {
 		n.FirstChild = child
 		n.LastChild = child
 	}
+	child.indexAdd()
 }
 
 // PrependChild 在 n 的子节点最前添加一个子节点。
@@ -356,6 +374,7 @@ func (n *Node) PrependChild(child *Node) {
 		n.FirstChild = child
 		n.LastChild = child
 	}
+	child.indexAdd()
 }
 
 // List 将 n 及其所有子节点按深度优先遍历添加到结果列表 ret 中。
@@ -640,3 +659,115 @@ const (
 
 	NodeTypeMaxVal NodeType = 1024 // 节点类型最大值
 )
+
+// Clone 深度复制 n 及其所有子节点，复制出的节点不挂在任何树上。
+//
+// freshIDs 为 true 时为复制出的节点重新生成 ID（包括 IAL 中的 id 属性），子树内的 IAL 节点也会同步更新。
+func (n *Node) Clone(freshIDs bool) (ret *Node) {
+	nodes := map[*Node]*Node{}
+	ids := map[string]string{}
+	ret = n.clone(freshIDs, nodes, ids)
+
+	Walk(ret, func(c *Node, entering bool) WalkStatus {
+		if !entering {
+			return WalkContinue
+		}
+
+		if 0 < len(c.FootnotesRefs) {
+			// 只保留指向复制子树内的脚注引用
+			var refs []*Node
+			for _, ref := range c.FootnotesRefs {
+				if cloned := nodes[ref]; nil != cloned {
+					refs = append(refs, cloned)
+				}
+			}
+			c.FootnotesRefs = refs
+		}
+
+		if (NodeKramdownBlockIAL == c.Type || NodeKramdownSpanIAL == c.Type) && 0 < len(ids) {
+			for oldID, newID := range ids {
+				c.Tokens = bytes.ReplaceAll(c.Tokens, []byte("id=\""+oldID+"\""), []byte("id=\""+newID+"\""))
+			}
+		}
+		return WalkContinue
+	})
+	return
+}
+
+func (n *Node) clone(freshIDs bool, nodes map[*Node]*Node, ids map[string]string) *Node {
+	ret := &Node{}
+	*ret = *n
+	ret.Parent, ret.Previous, ret.Next, ret.FirstChild, ret.LastChild = nil, nil, nil, nil, nil
+	ret.Children = nil
+	ret.index = nil
+	ret.Tokens = cloneBytes(n.Tokens)
+	ret.CodeBlockOpenFence = cloneBytes(n.CodeBlockOpenFence)
+	ret.CodeBlockInfo = cloneBytes(n.CodeBlockInfo)
+	ret.CodeBlockCloseFence = cloneBytes(n.CodeBlockCloseFence)
+	ret.LinkRefLabel = cloneBytes(n.LinkRefLabel)
+	ret.FootnotesRefLabel = cloneBytes(n.FootnotesRefLabel)
+	ret.HtmlEntityTokens = cloneBytes(n.HtmlEntityTokens)
+	if nil != n.TableAligns {
+		ret.TableAligns = append([]int{}, n.TableAligns...)
+	}
+	if nil != n.ListData {
+		listData := *n.ListData
+		listData.Marker = cloneBytes(n.ListData.Marker)
+		ret.ListData = &listData
+	}
+	if nil != n.KramdownIAL {
+		ret.KramdownIAL = make([][]string, 0, len(n.KramdownIAL))
+		for _, kv := range n.KramdownIAL {
+			ret.KramdownIAL = append(ret.KramdownIAL, append([]string{}, kv...))
+		}
+	}
+
+	if freshIDs {
+		if "" != ret.ID {
+			ret.ID = freshID(ret.ID, ids)
+		}
+		for _, kv := range ret.KramdownIAL {
+			if "id" == kv[0] && "" != kv[1] {
+				kv[1] = freshID(kv[1], ids)
+			}
+		}
+	}
+	nodes[n] = ret
+
+	for c := n.FirstChild; nil != c; c = c.Next {
+		ret.AppendChild(c.clone(freshIDs, nodes, ids))
+	}
+	return ret
+}
+
+// freshID 返回 oldID 对应的新 ID，同一个 oldID 总是对应同一个新 ID。
+func freshID(oldID string, ids map[string]string) string {
+	if ret, ok := ids[oldID]; ok {
+		return ret
+	}
+	ret := NewNodeID()
+	ids[oldID] = ret
+	return ret
+}
+
+func cloneBytes(b []byte) []byte {
+	if nil == b {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// ReplaceWith 使用 nodes 替换 n，n 会被从树上移除。nodes 为空时相当于 Unlink。
+func (n *Node) ReplaceWith(nodes ...*Node) {
+	for _, node := range nodes {
+		n.InsertBefore(node)
+	}
+	n.Unlink()
+}
+
+// Wrap 使用 wrapper 包裹 n：wrapper 会插入到 n 原来的位置，n 成为 wrapper 的最后一个子节点。
+func (n *Node) Wrap(wrapper *Node) *Node {
+	n.InsertBefore(wrapper)
+	wrapper.AppendChild(n)
+	return wrapper
+}
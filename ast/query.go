@@ -0,0 +1,411 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Selector 描述了编译后的节点选择器。
+//
+// 选择器语法参考 CSS 选择器：
+//   - 节点类型：使用 Str2NodeType 中的名称，可以省略 Node 前缀并且不区分大小写，比如 NodeHeading、heading，* 匹配任意类型
+//   - 属性：[name]、[name=value]、[name!=value]、[name^=value]、[name$=value]、[name*=value]，值可以使用单引号或者双引号包裹
+//   - 组合：空白（后代）、>（子节点）、+（紧邻的后一个兄弟节点）、~（后面的兄弟节点），兄弟关系会忽略 kramdown IAL 节点
+//   - 分组：使用逗号分隔多个选择器
+//
+// 属性名 level、id、text、lang、dest、title、checked、ordered 为常用字段的简写，其他名称依次尝试匹配节点字段名（比如 HeadingSetext、Typ）和 IAL 属性（比如 custom-status）。
+type Selector struct {
+	groups [][]*compound
+}
+
+// compound 描述了复合选择器，即不包含组合符的一段选择器。
+type compound struct {
+	combinator byte     // 与前一个复合选择器之间的组合符，' '、'>'、'+'、'~'，第一个复合选择器为 0
+	typ        NodeType // 节点类型，-1 表示任意类型
+	attrs      []*attrSelector
+}
+
+type attrSelector struct {
+	name  string
+	op    string // 空表示仅判断属性是否存在
+	value string
+}
+
+// Query 返回 root 及其后代节点中匹配选择器 selector 的节点，结果按文档顺序排列。
+func Query(root *Node, selector string) (ret []*Node, err error) {
+	s, err := ParseSelector(selector)
+	if nil != err {
+		return
+	}
+	return s.Query(root), nil
+}
+
+// ParseSelector 编译选择器 selector。
+func ParseSelector(selector string) (ret *Selector, err error) {
+	p := &selectorParser{src: selector}
+	ret = &Selector{}
+	for {
+		group, err := p.parseGroup()
+		if nil != err {
+			return nil, err
+		}
+		ret.groups = append(ret.groups, group)
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		if ',' != p.peek() {
+			return nil, p.error("unexpected character '" + string(p.peek()) + "'")
+		}
+		p.pos++
+	}
+	return
+}
+
+// Query 返回 root 及其后代节点中匹配选择器的节点，结果按文档顺序排列。
+func (s *Selector) Query(root *Node) (ret []*Node) {
+	Walk(root, func(n *Node, entering bool) WalkStatus {
+		if entering && s.match(root, n) {
+			ret = append(ret, n)
+		}
+		return WalkContinue
+	})
+	return
+}
+
+// Match 判断节点 n 是否匹配选择器，组合符向上或者向前查找节点时不受范围限制。
+func (s *Selector) Match(n *Node) bool {
+	return s.match(nil, n)
+}
+
+func (s *Selector) match(root, n *Node) bool {
+	for _, group := range s.groups {
+		if matchCompounds(root, group, len(group)-1, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCompounds 从右向左匹配，判断 n 是否匹配 compounds[:i+1]，root 用于限制向上查找祖先节点的范围。
+func matchCompounds(root *Node, compounds []*compound, i int, n *Node) bool {
+	c := compounds[i]
+	if !c.match(n) {
+		return false
+	}
+	if 0 == i {
+		return true
+	}
+
+	switch c.combinator {
+	case ' ':
+		for p := n.Parent; nil != p && n != root; p = p.Parent {
+			if matchCompounds(root, compounds, i-1, p) {
+				return true
+			}
+			if p == root {
+				break
+			}
+		}
+	case '>':
+		if nil != n.Parent && n != root {
+			return matchCompounds(root, compounds, i-1, n.Parent)
+		}
+	case '+':
+		if prev := previousSibling(n); nil != prev && n != root {
+			return matchCompounds(root, compounds, i-1, prev)
+		}
+	case '~':
+		for prev := previousSibling(n); nil != prev && n != root; prev = previousSibling(prev) {
+			if matchCompounds(root, compounds, i-1, prev) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// previousSibling 返回 n 的前一个兄弟节点，跳过 kramdown IAL 节点。
+func previousSibling(n *Node) (ret *Node) {
+	for ret = n.Previous; nil != ret && (NodeKramdownBlockIAL == ret.Type || NodeKramdownSpanIAL == ret.Type); ret = ret.Previous {
+	}
+	return
+}
+
+func (c *compound) match(n *Node) bool {
+	if -1 != c.typ && c.typ != n.Type {
+		return false
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *attrSelector) match(n *Node) bool {
+	value, ok := n.selectorAttr(a.name)
+	if !ok {
+		return "!=" == a.op
+	}
+
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "!=":
+		return value != a.value
+	case "^=":
+		return strings.HasPrefix(value, a.value)
+	case "$=":
+		return strings.HasSuffix(value, a.value)
+	case "*=":
+		return strings.Contains(value, a.value)
+	}
+	return false
+}
+
+// selectorAttr 返回选择器属性 name 在节点 n 上的值，ok 为 false 表示节点没有该属性。
+func (n *Node) selectorAttr(name string) (value string, ok bool) {
+	switch name {
+	case "level":
+		if NodeHeading == n.Type {
+			return strconv.Itoa(n.HeadingLevel), true
+		}
+		return "", false
+	case "id":
+		if id := n.IALAttr("id"); "" != id {
+			return id, true
+		}
+		return n.ID, "" != n.ID
+	case "text":
+		return n.Text(), true
+	case "lang":
+		if NodeCodeBlock == n.Type {
+			if info := n.ChildByType(NodeCodeBlockFenceInfoMarker); nil != info {
+				if fields := bytes.Fields(info.CodeBlockInfo); 0 < len(fields) {
+					return string(fields[0]), true
+				}
+			}
+		}
+		return "", false
+	case "dest":
+		if dest := n.ChildByType(NodeLinkDest); nil != dest {
+			return string(dest.Tokens), true
+		}
+		return "", false
+	case "title":
+		if title := n.ChildByType(NodeLinkTitle); nil != title {
+			return string(title.Tokens), true
+		}
+		return "", false
+	case "checked":
+		if NodeListItem == n.Type && nil != n.ListData && 3 == n.ListData.Typ {
+			return strconv.FormatBool(n.ListData.Checked), true
+		}
+		return "", false
+	case "ordered":
+		if (NodeList == n.Type || NodeListItem == n.Type) && nil != n.ListData {
+			return strconv.FormatBool(1 == n.ListData.Typ), true
+		}
+		return "", false
+	}
+
+	if unicode.IsUpper(rune(name[0])) {
+		v := reflect.ValueOf(n).Elem()
+		if field, ok := v.Type().FieldByName(name); ok {
+			if 1 < len(field.Index) && nil == n.ListData {
+				// 嵌入的 ListData 为空
+				return "", false
+			}
+			f := v.FieldByIndex(field.Index)
+			if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Uint8 {
+				return string(f.Bytes()), true
+			}
+			switch f.Kind() {
+			case reflect.String, reflect.Bool, reflect.Int, reflect.Uint8:
+				return fmt.Sprint(f.Interface()), true
+			}
+			return "", false
+		}
+	}
+
+	for _, kv := range n.KramdownIAL {
+		if name == kv[0] {
+			return kv[1], true
+		}
+	}
+	return "", false
+}
+
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) parseGroup() (ret []*compound, err error) {
+	var combinator byte
+	for {
+		hasSpace := p.skipSpace()
+		if p.eof() || ',' == p.peek() {
+			break
+		}
+		if c := p.peek(); '>' == c || '+' == c || '~' == c {
+			if 1 > len(ret) || 0 != combinator {
+				return nil, p.error("unexpected combinator '" + string(c) + "'")
+			}
+			combinator = c
+			p.pos++
+			continue
+		}
+		if 0 < len(ret) && 0 == combinator {
+			if !hasSpace {
+				return nil, p.error("unexpected character '" + string(p.peek()) + "'")
+			}
+			combinator = ' '
+		}
+
+		c, err := p.parseCompound()
+		if nil != err {
+			return nil, err
+		}
+		c.combinator = combinator
+		combinator = 0
+		ret = append(ret, c)
+	}
+
+	if 1 > len(ret) {
+		return nil, p.error("empty selector")
+	}
+	if 0 != combinator {
+		return nil, p.error("missing selector after combinator")
+	}
+	return
+}
+
+func (p *selectorParser) parseCompound() (ret *compound, err error) {
+	ret = &compound{typ: -1}
+	universal := '*' == p.peek()
+	if universal {
+		p.pos++
+	} else if name := p.ident(); "" != name {
+		ret.typ = selectorNodeType(name)
+		if -1 == ret.typ {
+			return nil, p.error("unknown node type [" + name + "]")
+		}
+	}
+
+	for !p.eof() && '[' == p.peek() {
+		p.pos++
+		p.skipSpace()
+		attr := &attrSelector{name: p.ident()}
+		if "" == attr.name {
+			return nil, p.error("missing attribute name")
+		}
+		p.skipSpace()
+		for _, op := range []string{"=", "!=", "^=", "$=", "*="} {
+			if strings.HasPrefix(p.src[p.pos:], op) {
+				attr.op = op
+				p.pos += len(op)
+				break
+			}
+		}
+		if "" != attr.op {
+			p.skipSpace()
+			if attr.value, err = p.value(); nil != err {
+				return nil, err
+			}
+			p.skipSpace()
+		}
+		if p.eof() || ']' != p.peek() {
+			return nil, p.error("missing ']'")
+		}
+		p.pos++
+		ret.attrs = append(ret.attrs, attr)
+	}
+
+	if -1 == ret.typ && 1 > len(ret.attrs) && !universal {
+		return nil, p.error("unexpected character '" + string(p.peek()) + "'")
+	}
+	return
+}
+
+func (p *selectorParser) ident() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !('a' <= c && 'z' >= c || 'A' <= c && 'Z' >= c || '0' <= c && '9' >= c || '-' == c || '_' == c || '.' == c) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *selectorParser) value() (string, error) {
+	if p.eof() {
+		return "", p.error("missing attribute value")
+	}
+	if quote := p.peek(); '"' == quote || '\'' == quote {
+		end := strings.IndexByte(p.src[p.pos+1:], quote)
+		if 0 > end {
+			return "", p.error("unterminated string")
+		}
+		ret := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return ret, nil
+	}
+	start := p.pos
+	for !p.eof() && ']' != p.peek() && ' ' != p.peek() {
+		p.pos++
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *selectorParser) skipSpace() (skipped bool) {
+	for !p.eof() && (' ' == p.peek() || '\t' == p.peek() || '\n' == p.peek()) {
+		p.pos++
+		skipped = true
+	}
+	return
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *selectorParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *selectorParser) error(msg string) error {
+	return errors.New("selector syntax error at position " + strconv.Itoa(p.pos) + ": " + msg)
+}
+
+// selectorNodeType 返回选择器中节点类型名称 name 对应的节点类型，name 可以省略 Node 前缀并且不区分大小写。
+func selectorNodeType(name string) NodeType {
+	for t := NodeDocument; t < NodeTypeMaxVal; t++ {
+		typeName := t.String()
+		if strings.EqualFold(typeName, name) || strings.EqualFold(strings.TrimPrefix(typeName, "Node"), name) {
+			return t
+		}
+	}
+	return -1
+}
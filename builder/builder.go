@@ -0,0 +1,452 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// Package builder 用于通过代码构造语法树。
+//
+// 构造出的节点和解析器生成的节点结构一致（包括各种标记符子节点），可以直接交给渲染器渲染，比如：
+//
+//	doc := builder.Document(
+//		builder.Heading(2, builder.Text("Release Notes")),
+//		builder.BulletList(
+//			builder.Item(builder.Paragraph(builder.Text("fix "), builder.Code("Format"))),
+//		),
+//	)
+//	tree := builder.Tree(doc, parse.NewOptions())
+//	markdown := render.NewFormatRenderer(tree, render.NewOptions()).Render()
+//
+// 通过 Text 构造的文本节点在挂到段落、标题等节点下时会将 Markdown 特殊字符转义，所以渲染结果重新解析后仍然得到同样的文本。
+package builder
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/lex"
+	"github.com/sunlightcs/lute/parse"
+)
+
+// Tree 使用根节点 root 和解析选项 options 构造语法树，root 不是文档节点时会被挂到一个新的文档节点下。
+func Tree(root *ast.Node, options *parse.Options) *parse.Tree {
+	if ast.NodeDocument != root.Type {
+		root = Document(root)
+	}
+	ret := &parse.Tree{Root: root, Context: &parse.Context{ParseOption: options}}
+	ret.Context.Tree = ret
+	return ret
+}
+
+// Document 构造文档节点。
+func Document(blocks ...*ast.Node) *ast.Node {
+	return container(&ast.Node{Type: ast.NodeDocument}, blocks)
+}
+
+// Paragraph 构造段落节点。
+func Paragraph(inlines ...*ast.Node) *ast.Node {
+	return inlineContainer(&ast.Node{Type: ast.NodeParagraph}, inlines)
+}
+
+// Heading 构造 ATX 标题节点，level 取值范围为 1~6。
+func Heading(level int, inlines ...*ast.Node) *ast.Node {
+	if 1 > level {
+		level = 1
+	} else if 6 < level {
+		level = 6
+	}
+	ret := &ast.Node{Type: ast.NodeHeading, HeadingLevel: level}
+	ret.AppendChild(&ast.Node{Type: ast.NodeHeadingC8hMarker, Tokens: []byte(strings.Repeat("#", level) + " ")})
+	return inlineContainer(ret, inlines)
+}
+
+// Blockquote 构造引述节点。
+func Blockquote(blocks ...*ast.Node) *ast.Node {
+	ret := &ast.Node{Type: ast.NodeBlockquote}
+	ret.AppendChild(&ast.Node{Type: ast.NodeBlockquoteMarker, Tokens: []byte("> ")})
+	return container(ret, blocks)
+}
+
+// ThematicBreak 构造分隔线节点。
+func ThematicBreak() *ast.Node {
+	return &ast.Node{Type: ast.NodeThematicBreak, Tokens: []byte("---")}
+}
+
+// CodeBlock 构造围栏代码块节点，lang 为代码语言，可以为空。围栏长度会根据代码内容自动选择。
+func CodeBlock(lang, code string) *ast.Node {
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	fenceLen := 3
+	if n := maxRun(code, '`') + 1; n > fenceLen {
+		fenceLen = n
+	}
+	fence := []byte(strings.Repeat("`", fenceLen))
+	info := []byte(lang)
+
+	ret := &ast.Node{Type: ast.NodeCodeBlock, IsFencedCodeBlock: true, CodeBlockFenceChar: '`', CodeBlockFenceLen: fenceLen,
+		CodeBlockOpenFence: fence, CodeBlockInfo: info, CodeBlockCloseFence: fence}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockFenceOpenMarker, Tokens: fence, CodeBlockFenceLen: fenceLen})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockFenceInfoMarker, CodeBlockInfo: info})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockCode, Tokens: []byte(code)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockFenceCloseMarker, Tokens: fence, CodeBlockFenceLen: fenceLen})
+	return ret
+}
+
+// MathBlock 构造数学公式块节点。
+func MathBlock(math string) *ast.Node {
+	ret := &ast.Node{Type: ast.NodeMathBlock}
+	ret.AppendChild(&ast.Node{Type: ast.NodeMathBlockOpenMarker})
+	ret.AppendChild(&ast.Node{Type: ast.NodeMathBlockContent, Tokens: []byte(strings.TrimSpace(math))})
+	ret.AppendChild(&ast.Node{Type: ast.NodeMathBlockCloseMarker})
+	return ret
+}
+
+// HTMLBlock 构造 HTML 块节点。
+func HTMLBlock(html string) *ast.Node {
+	return &ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte(strings.TrimSpace(html))}
+}
+
+// BulletList 构造无序列表节点，列表项通过 Item 或者 TaskItem 构造。包含任务列表项时构造任务列表。
+func BulletList(items ...*ast.Node) *ast.Node {
+	typ := 0
+	for _, item := range items {
+		if isTaskItem(item) {
+			typ = 3
+			break
+		}
+	}
+
+	listData := &ast.ListData{Typ: typ, Tight: true, BulletChar: '*', Padding: 2, Marker: []byte("*"), Num: -1}
+	ret := &ast.Node{Type: ast.NodeList, ListData: listData}
+	for _, item := range items {
+		data := *listData
+		data.Marker = []byte("*")
+		if nil != item.ListData {
+			data.Checked = item.ListData.Checked
+		}
+		item.ListData = &data
+		item.Tokens = []byte("*")
+		ret.AppendChild(item)
+	}
+	return ret
+}
+
+// OrderedList 构造有序列表节点，start 为起始序号，列表项通过 Item 构造。
+func OrderedList(start int, items ...*ast.Node) *ast.Node {
+	if 0 > start {
+		start = 0
+	}
+
+	marker := []byte(strconv.Itoa(start))
+	listData := &ast.ListData{Typ: 1, Tight: true, Start: start, Delimiter: '.', Padding: len(marker) + 2, Marker: marker, Num: start}
+	ret := &ast.Node{Type: ast.NodeList, ListData: listData}
+	for i, item := range items {
+		num := start + i
+		marker = []byte(strconv.Itoa(num))
+		item.ListData = &ast.ListData{Typ: 1, Tight: true, Start: num, Delimiter: '.', Padding: len(marker) + 2, Marker: marker, Num: num}
+		item.Tokens = marker
+		ret.AppendChild(item)
+	}
+	return ret
+}
+
+// Item 构造列表项节点，列表项需要挂到 BulletList 或者 OrderedList 下。
+func Item(blocks ...*ast.Node) *ast.Node {
+	return container(&ast.Node{Type: ast.NodeListItem, ListData: &ast.ListData{}}, blocks)
+}
+
+// TaskItem 构造任务列表项节点，checked 标识是否勾选。任务列表项的第一个子节点必须是段落。
+func TaskItem(checked bool, blocks ...*ast.Node) *ast.Node {
+	ret := Item(blocks...)
+	ret.ListData.Checked = checked
+	if nil == ret.FirstChild || ast.NodeParagraph != ret.FirstChild.Type {
+		ret.PrependChild(Paragraph())
+	}
+
+	paragraph := ret.FirstChild
+	tokens := []byte("[ ]")
+	if checked {
+		tokens = []byte("[X]")
+	}
+	if first := paragraph.FirstChild; nil != first && ast.NodeText == first.Type {
+		first.Tokens = append([]byte(" "), first.Tokens...)
+	} else {
+		paragraph.PrependChild(&ast.Node{Type: ast.NodeText, Tokens: []byte(" ")})
+	}
+	paragraph.PrependChild(&ast.Node{Type: ast.NodeTaskListItemMarker, Tokens: tokens, TaskListItemChecked: checked})
+	return ret
+}
+
+// Table 构造表格节点。aligns 为从左到右每列的对齐方式（0：默认对齐，1：左对齐，2：居中对齐，3：右对齐），可以为空；
+// head 为表头行，rows 为表体行，行通过 Row 构造。
+func Table(aligns []int, head *ast.Node, rows ...*ast.Node) *ast.Node {
+	cols := 0
+	for c := head.FirstChild; nil != c; c = c.Next {
+		cols++
+	}
+	tableAligns := make([]int, cols)
+	copy(tableAligns, aligns)
+
+	ret := &ast.Node{Type: ast.NodeTable, TableAligns: tableAligns}
+	thead := &ast.Node{Type: ast.NodeTableHead, TableAligns: tableAligns}
+	thead.AppendChild(tableRow(head, tableAligns))
+	ret.AppendChild(thead)
+	for _, row := range rows {
+		ret.AppendChild(tableRow(row, tableAligns))
+	}
+	return ret
+}
+
+// Row 构造表格行节点，单元格通过 Cell 构造。
+func Row(cells ...*ast.Node) *ast.Node {
+	return container(&ast.Node{Type: ast.NodeTableRow}, cells)
+}
+
+// Cell 构造表格单元格节点。
+func Cell(inlines ...*ast.Node) *ast.Node {
+	return inlineContainer(&ast.Node{Type: ast.NodeTableCell}, inlines)
+}
+
+// tableRow 将 row 的单元格数量补齐（或截断）到 aligns 的长度，并设置单元格的对齐方式。
+func tableRow(row *ast.Node, aligns []int) *ast.Node {
+	row.TableAligns = aligns
+	cell, i := row.FirstChild, 0
+	for ; i < len(aligns); i++ {
+		if nil == cell {
+			cell = Cell()
+			row.AppendChild(cell)
+		}
+		cell.TableCellAlign = aligns[i]
+		cell = cell.Next
+	}
+	for nil != cell {
+		next := cell.Next
+		cell.Unlink()
+		cell = next
+	}
+	return row
+}
+
+// Text 构造文本节点，text 为原样文本，不需要转义。
+func Text(text string) *ast.Node {
+	return &ast.Node{Type: ast.NodeText, Tokens: []byte(text)}
+}
+
+// Emphasis 构造强调节点。
+func Emphasis(inlines ...*ast.Node) *ast.Node {
+	return delimited(ast.NodeEmphasis, ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, "*", inlines)
+}
+
+// Strong 构造加粗节点。
+func Strong(inlines ...*ast.Node) *ast.Node {
+	return delimited(ast.NodeStrong, ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, "**", inlines)
+}
+
+// Strikethrough 构造删除线节点。
+func Strikethrough(inlines ...*ast.Node) *ast.Node {
+	return delimited(ast.NodeStrikethrough, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker, "~~", inlines)
+}
+
+// Code 构造代码节点。
+func Code(code string) *ast.Node {
+	markerLen := maxRun(code, '`') + 1
+	marker := strings.Repeat("`", markerLen)
+	openMarker, closeMarker := marker, marker
+	if "" != code && ('`' == code[0] || '`' == code[len(code)-1]) {
+		// 以反引号开头或结尾时需要用空格和标记符隔开，解析时首尾的一个空格会被剔除
+		openMarker, closeMarker = marker+" ", " "+marker
+	}
+	ret := &ast.Node{Type: ast.NodeCodeSpan, CodeMarkerLen: markerLen}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeSpanOpenMarker, Tokens: []byte(openMarker)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeSpanContent, Tokens: []byte(code)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeSpanCloseMarker, Tokens: []byte(closeMarker)})
+	return ret
+}
+
+// InlineMath 构造行级数学公式节点。
+func InlineMath(math string) *ast.Node {
+	ret := &ast.Node{Type: ast.NodeInlineMath}
+	ret.AppendChild(&ast.Node{Type: ast.NodeInlineMathOpenMarker})
+	ret.AppendChild(&ast.Node{Type: ast.NodeInlineMathContent, Tokens: []byte(math)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeInlineMathCloseMarker})
+	return ret
+}
+
+// Link 构造内联链接节点，dest 为链接地址，title 为链接标题，可以为空。
+func Link(dest, title string, inlines ...*ast.Node) *ast.Node {
+	ret := &ast.Node{Type: ast.NodeLink}
+	ret.AppendChild(&ast.Node{Type: ast.NodeOpenBracket, Tokens: []byte("[")})
+	for _, n := range escapeInlines(inlines, 0) {
+		ast.Walk(n, func(c *ast.Node, entering bool) ast.WalkStatus {
+			if entering && ast.NodeText == c.Type {
+				c.Type = ast.NodeLinkText
+			}
+			return ast.WalkContinue
+		})
+		ret.AppendChild(n)
+	}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCloseBracket, Tokens: []byte("]")})
+	linkDest(ret, dest, title)
+	return ret
+}
+
+// Image 构造图片节点，dest 为图片地址，alt 为替代文本，title 为图片标题，可以为空。
+func Image(dest, alt, title string) *ast.Node {
+	ret := &ast.Node{Type: ast.NodeImage}
+	ret.AppendChild(&ast.Node{Type: ast.NodeBang, Tokens: []byte("!")})
+	ret.AppendChild(&ast.Node{Type: ast.NodeOpenBracket, Tokens: []byte("[")})
+	ret.AppendChild(&ast.Node{Type: ast.NodeLinkText, Tokens: []byte(alt)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCloseBracket, Tokens: []byte("]")})
+	linkDest(ret, dest, title)
+	return ret
+}
+
+func linkDest(link *ast.Node, dest, title string) {
+	if strings.ContainsAny(dest, " ()") {
+		dest = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(dest)
+	}
+	link.AppendChild(&ast.Node{Type: ast.NodeOpenParen, Tokens: []byte("(")})
+	link.AppendChild(&ast.Node{Type: ast.NodeLinkDest, Tokens: []byte(dest)})
+	if "" != title {
+		title = strings.ReplaceAll(title, "\"", "&quot;")
+		link.AppendChild(&ast.Node{Type: ast.NodeLinkSpace, Tokens: []byte(" ")})
+		link.AppendChild(&ast.Node{Type: ast.NodeLinkTitle, Tokens: []byte(title)})
+	}
+	link.AppendChild(&ast.Node{Type: ast.NodeCloseParen, Tokens: []byte(")")})
+}
+
+// HardBreak 构造硬换行节点。
+func HardBreak() *ast.Node {
+	return &ast.Node{Type: ast.NodeHardBreak, Tokens: []byte("\n")}
+}
+
+// SoftBreak 构造软换行节点。
+func SoftBreak() *ast.Node {
+	return &ast.Node{Type: ast.NodeSoftBreak, Tokens: []byte("\n")}
+}
+
+// delimited 构造由开始、结束标记符包裹的行级节点。
+func delimited(typ, openTyp, closeTyp ast.NodeType, marker string, inlines []*ast.Node) *ast.Node {
+	ret := &ast.Node{Type: typ}
+	ret.AppendChild(&ast.Node{Type: openTyp, Tokens: []byte(marker)})
+	for _, n := range escapeInlines(inlines, 0) {
+		ret.AppendChild(n)
+	}
+	ret.AppendChild(&ast.Node{Type: closeTyp, Tokens: []byte(marker)})
+	return ret
+}
+
+func container(parent *ast.Node, children []*ast.Node) *ast.Node {
+	for _, child := range children {
+		if nil != child {
+			parent.AppendChild(child)
+		}
+	}
+	return parent
+}
+
+// inlineContainer 将 inlines 挂到叶子块节点 parent 下。段落行首的块级标记符和标题结尾的 # 也会被转义。
+func inlineContainer(parent *ast.Node, inlines []*ast.Node) *ast.Node {
+	return container(parent, escapeInlines(inlines, parent.Type))
+}
+
+// escapeInlines 将 inlines 中文本节点里的 Markdown 特殊字符拆分为转义节点，block 为 inlines 所在的叶子块节点类型。
+func escapeInlines(inlines []*ast.Node, block ast.NodeType) (ret []*ast.Node) {
+	last := len(inlines) - 1
+	for 0 <= last && nil == inlines[last] {
+		last--
+	}
+
+	lineStart := ast.NodeParagraph == block
+	for i, n := range inlines {
+		if nil == n {
+			continue
+		}
+		if ast.NodeText != n.Type || nil != n.Parent {
+			ret = append(ret, n)
+			lineStart = ast.NodeParagraph == block && (ast.NodeSoftBreak == n.Type || ast.NodeHardBreak == n.Type)
+			continue
+		}
+
+		tokens := n.Tokens
+		listDelimiter, closingHash := -1, -1
+		if lineStart {
+			// 行首的 1. 和 1) 会被解析为有序列表
+			digits := 0
+			for digits < len(tokens) && lex.IsDigit(tokens[digits]) {
+				digits++
+			}
+			if 0 < digits && 10 > digits && digits < len(tokens) && ('.' == tokens[digits] || ')' == tokens[digits]) {
+				listDelimiter = digits
+			}
+		}
+		if ast.NodeHeading == block && i == last {
+			// 标题结尾的 # 会被解析为结束标记符
+			hashes := len(tokens)
+			for 0 < hashes && '#' == tokens[hashes-1] {
+				hashes--
+			}
+			if hashes < len(tokens) {
+				closingHash = hashes
+			}
+		}
+
+		var text []byte
+		for j := 0; j < len(tokens); j++ {
+			c := tokens[j]
+			escape := bytes.IndexByte(escapeChars, c) >= 0 || listDelimiter == j || closingHash == j
+			if !escape && 0 == j && lineStart {
+				escape = bytes.IndexByte(lineStartEscapeChars, c) >= 0
+			}
+			if !escape {
+				text = append(text, c)
+				continue
+			}
+
+			if 0 < len(text) {
+				ret = append(ret, Text(string(text)))
+				text = nil
+			}
+			backslash := &ast.Node{Type: ast.NodeBackslash}
+			backslash.AppendChild(&ast.Node{Type: ast.NodeBackslashContent, Tokens: []byte{c}})
+			ret = append(ret, backslash)
+		}
+		if 0 < len(text) || 0 == len(tokens) {
+			ret = append(ret, Text(string(text)))
+		}
+		lineStart = false
+	}
+	return
+}
+
+var (
+	escapeChars          = []byte("\\`*_[]<~$|&")
+	lineStartEscapeChars = []byte("#>-+=")
+)
+
+func isTaskItem(item *ast.Node) bool {
+	p := item.FirstChild
+	return nil != p && ast.NodeParagraph == p.Type && nil != p.FirstChild && ast.NodeTaskListItemMarker == p.FirstChild.Type
+}
+
+func maxRun(s string, c byte) (ret int) {
+	run := 0
+	for i := 0; i < len(s); i++ {
+		if c == s[i] {
+			run++
+			if run > ret {
+				ret = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return
+}
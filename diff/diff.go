@@ -0,0 +1,252 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// Package diff 实现了基于内容块 ID 的文档结构化差异比较。
+package diff
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+)
+
+// 变更操作。
+const (
+	OpInsert = "insert" // 插入内容块
+	OpDelete = "delete" // 删除内容块
+	OpMove   = "move"   // 移动内容块
+	OpModify = "modify" // 修改内容块
+)
+
+// similarityThreshold 内容相似度匹配阈值，ID 无法匹配的内容块相似度不低于该值时视为同一个内容块。
+const similarityThreshold = 0.5
+
+// Change 描述了一个内容块变更。
+type Change struct {
+	Op          string          `json:"op"`                    // 变更操作，insert、delete、move 或者 modify
+	ID          string          `json:"id,omitempty"`          // 内容块 ID，删除操作时为旧内容块 ID
+	OldID       string          `json:"oldID,omitempty"`       // 通过内容相似度匹配且 ID 不同时的旧内容块 ID
+	Type        string          `json:"type"`                  // 内容块节点类型
+	OldIndex    int             `json:"oldIndex"`              // 在旧文档内容块序列中的位置，插入操作时为 -1
+	NewIndex    int             `json:"newIndex"`              // 在新文档内容块序列中的位置，删除操作时为 -1
+	OldParentID string          `json:"oldParentID,omitempty"` // 旧文档中父容器块 ID
+	NewParentID string          `json:"newParentID,omitempty"` // 新文档中父容器块 ID
+	Old         string          `json:"old,omitempty"`         // 旧内容块 Markdown
+	New         string          `json:"new,omitempty"`         // 新内容块 Markdown
+	Inlines     []*InlineChange `json:"inlines,omitempty"`     // 修改操作时的行级差异
+}
+
+// InlineChange 描述了行级差异片段。
+type InlineChange struct {
+	Op   string `json:"op"`   // equal、insert 或者 delete
+	Text string `json:"text"` // 片段文本
+}
+
+// Block 描述了参与比较的内容块。
+type Block struct {
+	Node     *ast.Node // 内容块节点
+	ID       string    // 内容块 ID
+	ParentID string    // 父容器块 ID
+	Markdown string    // 格式化后的 Markdown 文本，不包含 IAL
+	Index    int       // 在内容块序列中的位置
+}
+
+// Blocks 按文档顺序返回 tree 中的叶子内容块（不包含其他内容块的内容块）。
+func Blocks(tree *parse.Tree) (ret []*Block) {
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || !n.IsBlock() || n.IsContainerBlock() || ast.NodeKramdownBlockIAL == n.Type {
+			return ast.WalkContinue
+		}
+
+		block := &Block{Node: n, ID: nodeID(n), Markdown: blockMarkdown(tree, n), Index: len(ret)}
+		for p := n.Parent; nil != p; p = p.Parent {
+			if id := nodeID(p); "" != id {
+				block.ParentID = id
+				break
+			}
+		}
+		ret = append(ret, block)
+		return ast.WalkSkipChildren
+	})
+	return
+}
+
+// Diff 比较新旧两棵语法树，返回内容块变更列表。内容块优先通过 IAL id 匹配，无法匹配的内容块再通过内容相似度匹配。
+//
+// 返回的变更先按旧文档顺序列出删除操作，再按新文档顺序列出插入、移动和修改操作。
+func Diff(oldTree, newTree *parse.Tree) (ret []*Change) {
+	oldBlocks, newBlocks := Blocks(oldTree), Blocks(newTree)
+	pairs := Match(oldBlocks, newBlocks)
+
+	matchedOld := map[*Block]*Block{}
+	for _, pair := range pairs {
+		matchedOld[pair[0]] = pair[1]
+	}
+	for _, old := range oldBlocks {
+		if _, ok := matchedOld[old]; !ok {
+			ret = append(ret, &Change{Op: OpDelete, ID: old.ID, Type: old.Node.Type.String(), OldIndex: old.Index, NewIndex: -1,
+				OldParentID: old.ParentID, Old: old.Markdown})
+		}
+	}
+
+	matchedNew := map[*Block]*Block{}
+	for _, pair := range pairs {
+		matchedNew[pair[1]] = pair[0]
+	}
+	stable := stableBlocks(pairs)
+	for _, cur := range newBlocks {
+		old, ok := matchedNew[cur]
+		if !ok {
+			ret = append(ret, &Change{Op: OpInsert, ID: cur.ID, Type: cur.Node.Type.String(), OldIndex: -1, NewIndex: cur.Index,
+				NewParentID: cur.ParentID, New: cur.Markdown})
+			continue
+		}
+
+		change := &Change{ID: cur.ID, Type: cur.Node.Type.String(), OldIndex: old.Index, NewIndex: cur.Index,
+			OldParentID: old.ParentID, NewParentID: cur.ParentID}
+		if old.ID != cur.ID {
+			change.OldID = old.ID
+		}
+		if !stable[cur] || old.ParentID != cur.ParentID {
+			move := *change
+			move.Op = OpMove
+			ret = append(ret, &move)
+		}
+		if old.Markdown != cur.Markdown || old.Node.Type != cur.Node.Type {
+			change.Op = OpModify
+			change.Old, change.New = old.Markdown, cur.Markdown
+			change.Inlines = Inline(old.Markdown, cur.Markdown)
+			ret = append(ret, change)
+		}
+	}
+	return
+}
+
+// Match 匹配新旧内容块，返回 [旧内容块, 新内容块] 对，按新内容块顺序排列。
+//
+// 匹配依次使用：相同 ID、相同类型且 Markdown 完全一致、相同类型且内容相似度不低于阈值。
+func Match(oldBlocks, newBlocks []*Block) (ret [][2]*Block) {
+	matched := map[*Block]*Block{}
+	usedOld := map[*Block]bool{}
+
+	oldByID, newIDs := map[string]*Block{}, map[string]bool{}
+	for _, old := range oldBlocks {
+		if "" != old.ID {
+			oldByID[old.ID] = old
+		}
+	}
+	for _, cur := range newBlocks {
+		newIDs[cur.ID] = true
+	}
+	// 旧内容块的 ID 在新文档中已经不存在时才参与内容匹配
+	fallbackCandidate := func(old *Block) bool {
+		return !usedOld[old] && ("" == old.ID || !newIDs[old.ID])
+	}
+	for _, cur := range newBlocks {
+		if old := oldByID[cur.ID]; "" != cur.ID && nil != old && !usedOld[old] {
+			matched[cur] = old
+			usedOld[old] = true
+		}
+	}
+
+	// 没有 ID 或者 ID 无法匹配的内容块使用内容匹配
+	for _, cur := range newBlocks {
+		if nil != matched[cur] {
+			continue
+		}
+		for _, old := range oldBlocks {
+			if fallbackCandidate(old) && old.Node.Type == cur.Node.Type && old.Markdown == cur.Markdown {
+				matched[cur] = old
+				usedOld[old] = true
+				break
+			}
+		}
+	}
+	for _, cur := range newBlocks {
+		if nil != matched[cur] {
+			continue
+		}
+		var best *Block
+		var bestScore float64
+		for _, old := range oldBlocks {
+			if !fallbackCandidate(old) || old.Node.Type != cur.Node.Type {
+				continue
+			}
+			if score := Similarity(old.Markdown, cur.Markdown); score >= similarityThreshold && score > bestScore {
+				best, bestScore = old, score
+			}
+		}
+		if nil != best {
+			matched[cur] = best
+			usedOld[best] = true
+		}
+	}
+
+	for _, cur := range newBlocks {
+		if old := matched[cur]; nil != old {
+			ret = append(ret, [2]*Block{old, cur})
+		}
+	}
+	return
+}
+
+// stableBlocks 返回相对顺序没有变化的新内容块，即旧位置序列的最长递增子序列。
+func stableBlocks(pairs [][2]*Block) (ret map[*Block]bool) {
+	ret = map[*Block]bool{}
+	n := len(pairs)
+	if 1 > n {
+		return
+	}
+
+	lens := make([]int, n)
+	prevs := make([]int, n)
+	best := 0
+	for i := 0; i < n; i++ {
+		lens[i], prevs[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if pairs[j][0].Index < pairs[i][0].Index && lens[j]+1 > lens[i] {
+				lens[i], prevs[i] = lens[j]+1, j
+			}
+		}
+		if lens[i] > lens[best] {
+			best = i
+		}
+	}
+	for i := best; -1 != i; i = prevs[i] {
+		ret[pairs[i][1]] = true
+	}
+	return
+}
+
+func nodeID(n *ast.Node) string {
+	if ast.NodeDocument == n.Type {
+		return ""
+	}
+	if id := n.IALAttr("id"); "" != id {
+		return id
+	}
+	return n.ID
+}
+
+func blockMarkdown(tree *parse.Tree, node *ast.Node) string {
+	options := render.NewOptions()
+	options.AutoSpace = false
+	options.FixTermTypo = false
+	renderer := render.NewFormatRenderer(tree, options)
+	renderer.Writer = &bytes.Buffer{}
+	renderer.NodeWriterStack = append(renderer.NodeWriterStack, renderer.Writer)
+	ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+		return renderer.RendererFuncs[n.Type](n, entering)
+	})
+	return strings.TrimSpace(renderer.Writer.String())
+}
@@ -0,0 +1,173 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package diff
+
+import (
+	"unicode"
+)
+
+// maxInlineTokens 行级比较的最大分词数，超出时整体作为删除和插入处理。
+const maxInlineTokens = 4096
+
+// Inline 返回 oldText 到 newText 的行级差异。西文按单词切分，中文等其他文字按字切分。
+func Inline(oldText, newText string) (ret []*InlineChange) {
+	a, b := tokenize(oldText), tokenize(newText)
+	if maxInlineTokens < len(a) || maxInlineTokens < len(b) {
+		if "" != oldText {
+			ret = append(ret, &InlineChange{Op: "delete", Text: oldText})
+		}
+		if "" != newText {
+			ret = append(ret, &InlineChange{Op: "insert", Text: newText})
+		}
+		return
+	}
+	return appendLCSChanges(ret, a, b)
+}
+
+// Similarity 返回 a 和 b 的相似度，取值范围为 [0, 1]。
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ta, tb := tokenize(a), tokenize(b)
+	if 0 == len(ta)+len(tb) {
+		return 1
+	}
+	if maxInlineTokens < len(ta) || maxInlineTokens < len(tb) {
+		return 0
+	}
+	lengths := lcsLengths(ta, tb)
+	return 2 * float64(lengths[len(tb)]) / float64(len(ta)+len(tb))
+}
+
+func appendInline(changes []*InlineChange, op, text string) []*InlineChange {
+	if length := len(changes); 0 < length && op == changes[length-1].Op {
+		changes[length-1].Text += text
+		return changes
+	}
+	return append(changes, &InlineChange{Op: op, Text: text})
+}
+
+// appendLCSChanges 按照 a 和 b 的最长公共子序列将 a 到 b 的差异追加到 changes 中。
+//
+// 使用 Hirschberg 算法，只需要和 b 的长度成正比的空间，避免为两个长段落分配完整的动态规划表。
+func appendLCSChanges(changes []*InlineChange, a, b []string) []*InlineChange {
+	// 公共前缀和后缀直接作为相同部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		changes = appendInline(changes, "equal", a[prefix])
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	commonSuffix := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case 0 == len(a):
+		for _, token := range b {
+			changes = appendInline(changes, "insert", token)
+		}
+	case 0 == len(b):
+		for _, token := range a {
+			changes = appendInline(changes, "delete", token)
+		}
+	case 1 == len(a):
+		k := 0
+		for k < len(b) && a[0] != b[k] {
+			k++
+		}
+		if k == len(b) {
+			changes = appendInline(changes, "delete", a[0])
+		}
+		for j, token := range b {
+			if j == k {
+				changes = appendInline(changes, "equal", token)
+			} else {
+				changes = appendInline(changes, "insert", token)
+			}
+		}
+	default:
+		// 将 a 从中间分开，找到 b 的分割点使两部分的最长公共子序列长度之和最大
+		mid := len(a) / 2
+		forward := lcsLengths(a[:mid], b)
+		backward := lcsLengths(reversed(a[mid:]), reversed(b))
+		k, best := 0, -1
+		for j := 0; j <= len(b); j++ {
+			if length := forward[j] + backward[len(b)-j]; length > best {
+				k, best = j, length
+			}
+		}
+		changes = appendLCSChanges(changes, a[:mid], b[:k])
+		changes = appendLCSChanges(changes, a[mid:], b[k:])
+	}
+
+	for _, token := range commonSuffix {
+		changes = appendInline(changes, "equal", token)
+	}
+	return changes
+}
+
+// lcsLengths 返回 a 和 b 的各个前缀 b[:j] 的最长公共子序列长度，只使用两行空间。
+func lcsLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else if prev[j+1] >= cur[j] {
+				cur[j+1] = prev[j+1]
+			} else {
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func reversed(tokens []string) []string {
+	ret := make([]string, len(tokens))
+	for i, token := range tokens {
+		ret[len(tokens)-1-i] = token
+	}
+	return ret
+}
+
+// tokenize 将 text 切分为单词、空白、标点以及单个非西文字符。
+func tokenize(text string) (ret []string) {
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case isWordRune(r):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+		case unicode.IsSpace(r):
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+		default:
+			i++
+		}
+		ret = append(ret, string(runes[start:i]))
+	}
+	return
+}
+
+func isWordRune(r rune) bool {
+	return r < 0x2E80 && (unicode.IsLetter(r) || unicode.IsDigit(r) || '_' == r)
+}
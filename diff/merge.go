@@ -0,0 +1,271 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package diff
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+	"github.com/sunlightcs/lute/util"
+)
+
+// 合并冲突原因。
+const (
+	ConflictModifyModify = "modify/modify" // 双方都修改了同一个内容块
+	ConflictModifyDelete = "modify/delete" // 本地修改、对方删除
+	ConflictDeleteModify = "delete/modify" // 本地删除、对方修改
+)
+
+// Conflict 描述了一个合并冲突。
+type Conflict struct {
+	ID     string `json:"id,omitempty"`     // 基础版本中的内容块 ID
+	Reason string `json:"reason"`           // 冲突原因
+	Base   string `json:"base,omitempty"`   // 基础版本 Markdown
+	Ours   string `json:"ours,omitempty"`   // 本地版本 Markdown
+	Theirs string `json:"theirs,omitempty"` // 对方版本 Markdown
+}
+
+// unit 描述了参与合并的顶层内容块及其 IAL 节点。
+type unit struct {
+	*Block
+	ial *ast.Node // 紧随内容块的 kramdown 块级 IAL 节点
+	key string    // 比较用的键，由 Markdown 和除 id 以外的 IAL 属性组成
+}
+
+// Merge 以 base 为基础版本对 ours 和 theirs 进行三方合并，以顶层内容块为合并粒度，返回合并后的语法树和冲突列表。
+//
+// 双方不重叠的内容块修改（插入、删除、修改）会自动合并；双方都修改了同一个内容块，或者一方修改另一方删除时，
+// 会生成 Git 冲突标记块（NodeGitConflict）并记录冲突。内容块顺序以调整了顺序的一方为准，双方都调整时以 ours 为准。
+// 合并时会移动 ours 和 theirs 中的节点，调用后不应再使用这两棵树。
+func Merge(base, ours, theirs *parse.Tree) (ret *parse.Tree, conflicts []*Conflict) {
+	baseUnits, ourUnits, theirUnits := units(base), units(ours), units(theirs)
+	ourBase, ourInserts := mergeMatch(baseUnits, ourUnits)
+	theirBase, theirInserts := mergeMatch(baseUnits, theirUnits)
+
+	ret = &parse.Tree{Name: ours.Name, ID: ours.ID, Context: ours.Context, Root: &ast.Node{Type: ast.NodeDocument, ID: ours.Root.ID}}
+	ret.Root.KramdownIAL = ours.Root.KramdownIAL
+	var docIAL *ast.Node
+	if last := ours.Root.LastChild; nil != last && ast.NodeKramdownBlockIAL == last.Type && util.IsDocIAL(last.Tokens) {
+		docIAL = last
+	}
+
+	emitted := map[string]bool{}
+	emitInserts := func(anchor *unit) {
+		for _, inserts := range [][]*unit{ourInserts[anchor], theirInserts[anchor]} {
+			for _, u := range inserts {
+				if emitted[u.key] { // 双方插入了相同的内容块
+					continue
+				}
+				emitted[u.key] = true
+				appendUnit(ret.Root, u)
+			}
+		}
+	}
+
+	emitInserts(nil)
+	for _, b := range mergeOrder(baseUnits, ourUnits, theirUnits, ourBase, theirBase) {
+		our, their := ourBase[b], theirBase[b]
+		ourChanged := nil == our || our.key != b.key
+		theirChanged := nil == their || their.key != b.key
+		switch {
+		case !ourChanged && !theirChanged:
+			appendUnit(ret.Root, our)
+		case ourChanged && !theirChanged:
+			if nil != our {
+				appendUnit(ret.Root, our)
+			}
+		case !ourChanged && theirChanged:
+			if nil != their {
+				appendUnit(ret.Root, their)
+			}
+		case nil == our && nil == their:
+			// 双方都删除了
+		case nil != our && nil != their && our.key == their.key:
+			appendUnit(ret.Root, our)
+		default:
+			conflict := &Conflict{ID: b.ID, Reason: ConflictModifyModify, Base: unitMarkdown(base, b)}
+			if nil == their {
+				conflict.Reason = ConflictModifyDelete
+			} else if nil == our {
+				conflict.Reason = ConflictDeleteModify
+			}
+			if nil != our {
+				conflict.Ours = unitMarkdown(ours, our)
+			}
+			if nil != their {
+				conflict.Theirs = unitMarkdown(theirs, their)
+			}
+			conflicts = append(conflicts, conflict)
+			ret.Root.AppendChild(conflictNode(conflict))
+		}
+		emitInserts(b)
+	}
+	if nil != docIAL {
+		ret.Root.AppendChild(docIAL)
+	}
+	return
+}
+
+// units 返回 tree 的顶层内容块。
+func units(tree *parse.Tree) (ret []*unit) {
+	for n := tree.Root.FirstChild; nil != n; n = n.Next {
+		if ast.NodeKramdownBlockIAL == n.Type {
+			continue
+		}
+
+		u := &unit{Block: &Block{Node: n, ID: nodeID(n), Markdown: blockMarkdown(tree, n), Index: len(ret)}}
+		if next := n.Next; nil != next && ast.NodeKramdownBlockIAL == next.Type && !util.IsDocIAL(next.Tokens) {
+			u.ial = next
+		}
+		u.key = u.Markdown
+		for _, kv := range n.KramdownIAL {
+			if "id" != kv[0] {
+				u.key += "\n" + kv[0] + "=" + kv[1]
+			}
+		}
+		ret = append(ret, u)
+	}
+	return
+}
+
+// mergeMatch 将 side 的内容块匹配到 base 上，返回基础内容块到 side 内容块的映射，以及按锚点（前一个已匹配的基础内容块，nil 表示文档开头）分组的新插入内容块。
+func mergeMatch(base, side []*unit) (matched map[*unit]*unit, inserts map[*unit][]*unit) {
+	baseBlocks, sideBlocks := make([]*Block, len(base)), make([]*Block, len(side))
+	blockUnits := map[*Block]*unit{}
+	for i, u := range base {
+		baseBlocks[i] = u.Block
+		blockUnits[u.Block] = u
+	}
+	for i, u := range side {
+		sideBlocks[i] = u.Block
+		blockUnits[u.Block] = u
+	}
+
+	matched, inserts = map[*unit]*unit{}, map[*unit][]*unit{}
+	sideMatched := map[*unit]*unit{}
+	for _, pair := range Match(baseBlocks, sideBlocks) {
+		b, s := blockUnits[pair[0]], blockUnits[pair[1]]
+		matched[b] = s
+		sideMatched[s] = b
+	}
+	var anchor *unit
+	for _, s := range side {
+		if b := sideMatched[s]; nil != b {
+			anchor = b
+			continue
+		}
+		inserts[anchor] = append(inserts[anchor], s)
+	}
+	return
+}
+
+// mergeOrder 返回合并后基础内容块的顺序，被某一方删除的内容块放在其基础版本前一个内容块之后。
+func mergeOrder(base, ours, theirs []*unit, ourBase, theirBase map[*unit]*unit) (ret []*unit) {
+	primary, primaryBase := ours, ourBase
+	if !reordered(base, ours, ourBase) && reordered(base, theirs, theirBase) {
+		primary, primaryBase = theirs, theirBase
+	}
+
+	sideBase := map[*unit]*unit{}
+	for b, s := range primaryBase {
+		sideBase[s] = b
+	}
+	placed := map[*unit]bool{}
+	for _, s := range primary {
+		if b := sideBase[s]; nil != b {
+			ret = append(ret, b)
+			placed[b] = true
+		}
+	}
+
+	for i, b := range base {
+		if placed[b] {
+			continue
+		}
+		pos := 0
+		for j := i - 1; 0 <= j; j-- {
+			if placed[base[j]] {
+				for k, r := range ret {
+					if r == base[j] {
+						pos = k + 1
+						break
+					}
+				}
+				break
+			}
+		}
+		ret = append(ret[:pos], append([]*unit{b}, ret[pos:]...)...)
+		placed[b] = true
+	}
+	return
+}
+
+// reordered 判断 side 是否调整了基础内容块的相对顺序。
+func reordered(base, side []*unit, sideBase map[*unit]*unit) bool {
+	baseIndex := map[*unit]int{}
+	for b, s := range sideBase {
+		baseIndex[s] = b.Index
+	}
+	last := -1
+	for _, s := range side {
+		if index, ok := baseIndex[s]; ok {
+			if index < last {
+				return true
+			}
+			last = index
+		}
+	}
+	return false
+}
+
+func appendUnit(root *ast.Node, u *unit) {
+	ial := u.ial
+	root.AppendChild(u.Node)
+	if nil != ial {
+		root.AppendChild(ial)
+	}
+}
+
+// unitMarkdown 返回内容块 u 包含 IAL 的 Markdown。
+func unitMarkdown(tree *parse.Tree, u *unit) string {
+	options := render.NewOptions()
+	options.AutoSpace = false
+	options.FixTermTypo = false
+	options.KramdownBlockIAL = true
+	options.KramdownSpanIAL = true
+	renderer := render.NewFormatRenderer(tree, options)
+	renderer.Writer = &bytes.Buffer{}
+	renderer.NodeWriterStack = append(renderer.NodeWriterStack, renderer.Writer)
+	nodes := []*ast.Node{u.Node}
+	if nil != u.ial {
+		nodes = append(nodes, u.ial)
+	}
+	for _, node := range nodes {
+		ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+			return renderer.RendererFuncs[n.Type](n, entering)
+		})
+	}
+	return strings.TrimSpace(renderer.Writer.String())
+}
+
+// conflictNode 构造 Git 冲突标记块。
+func conflictNode(conflict *Conflict) (ret *ast.Node) {
+	content := conflict.Ours + "\n=======\n" + conflict.Theirs
+	content = strings.TrimSpace(content)
+	ret = &ast.Node{Type: ast.NodeGitConflict}
+	ret.AppendChild(&ast.Node{Type: ast.NodeGitConflictOpenMarker, Tokens: []byte("<<<<<<< ours")})
+	ret.AppendChild(&ast.Node{Type: ast.NodeGitConflictContent, Tokens: []byte(content)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeGitConflictCloseMarker, Tokens: []byte(">>>>>>> theirs")})
+	return
+}
@@ -0,0 +1,153 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+	"github.com/sunlightcs/lute/util"
+)
+
+// FormatMinimal 以最小改动的方式格式化 markdown。
+//
+// 没有违反格式化样式的顶层块原样输出源码，只有违反样式（比如列表标记符、折行宽度、中西文空格）的块才重新格式化，
+// 所以对已经格式化过的文本再次调用不会产生任何改动。
+func (lute *Lute) FormatMinimal(name string, markdown []byte) (formatted []byte) {
+	tree, err := lute.parse(name, markdown)
+	if nil != err {
+		formatted = []byte(err.Error())
+		return
+	}
+	formatted = lute.FormatTreeMinimal(tree, markdown)
+	return
+}
+
+// FormatMinimalStr 接受 string 类型的 markdown 后直接调用 FormatMinimal 进行处理。
+func (lute *Lute) FormatMinimalStr(name, markdown string) (formatted string) {
+	formattedBytes := lute.FormatMinimal(name, []byte(markdown))
+	formatted = util.BytesToStr(formattedBytes)
+	return
+}
+
+// FormatTreeMinimal 以最小改动的方式格式化由 markdown 解析得到的语法树 tree。
+//
+// 除了违反格式化样式的块，tree 中被修改过（和重新解析 markdown 得到的块不同）的顶层块也会重新格式化，被删除的块不再输出。
+func (lute *Lute) FormatTreeMinimal(tree *parse.Tree, markdown []byte) (formatted []byte) {
+	blocks := sourceBlocks(tree)
+	if 1 > len(blocks) {
+		return markdown
+	}
+
+	// 重新解析源码，用于确定各个块在源码中的范围并判断块是否被修改过
+	original := parse.Parse("", markdown, lute.ParseOptions)
+	neutralOptions := neutralFormatOptions(lute.RenderOptions)
+	originalRenderer := render.NewFormatRenderer(original, neutralOptions)
+	originalBlocks := map[int][]byte{}
+	var starts []int
+	for _, block := range sourceBlocks(original) {
+		originalBlocks[block.line] = originalRenderer.RenderBlock(block.nodes...)
+		starts = append(starts, block.line)
+	}
+
+	lines := strings.Split(util.BytesToStr(markdown), "\n")
+	styledRenderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	neutralRenderer := render.NewFormatRenderer(tree, neutralOptions)
+	out := append([]string{}, lines[:blocks[0].line-1]...)
+	prevKept := true
+	for i, block := range blocks {
+		end := len(lines) + 1
+		for _, start := range starts {
+			if start > block.line {
+				end = start
+				break
+			}
+		}
+		textEnd := end
+		for textEnd-1 > block.line && "" == strings.TrimSpace(lines[textEnd-2]) {
+			textEnd--
+		}
+
+		styled := styledRenderer.RenderBlock(block.nodes...)
+		neutral := neutralRenderer.RenderBlock(block.nodes...)
+		originalBlock, ok := originalBlocks[block.line]
+		kept := ok && bytes.Equal(styled, neutral) && bytes.Equal(neutral, originalBlock)
+
+		separator := lines[textEnd-1 : end-1]
+		if 0 < i && (!kept || !prevKept) && 0 < len(out) && "" != strings.TrimSpace(out[len(out)-1]) {
+			// 重新格式化的块和相邻的块之间至少保留一个空行
+			out = append(out, "")
+		}
+		if kept {
+			out = append(out, lines[block.line-1:textEnd-1]...)
+		} else {
+			out = append(out, strings.Split(util.BytesToStr(styled), "\n")...)
+		}
+
+		if i < len(blocks)-1 {
+			out = append(out, separator...)
+		} else if kept {
+			out = append(out, lines[textEnd-1:]...)
+		} else {
+			out = append(out, "")
+		}
+		prevKept = kept
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// sourceBlock 描述了源码中从同一行开始的顶层块。
+type sourceBlock struct {
+	line  int         // 起始行号
+	nodes []*ast.Node // 顶层块以及跟在它后面没有行号的节点（比如内联属性列表）
+}
+
+// sourceBlocks 将语法树 tree 根节点下的块按源码起始行分组，没有行号的节点归入前一组。
+func sourceBlocks(tree *parse.Tree) (ret []*sourceBlock) {
+	for n := tree.Root.FirstChild; nil != n; n = n.Next {
+		line := n.SourceLine
+		if 0 == len(ret) {
+			if 1 > line {
+				// YAML Front Matter 没有记录行号，它只能出现在第一行
+				line = 1
+			}
+			ret = append(ret, &sourceBlock{line: line, nodes: []*ast.Node{n}})
+			continue
+		}
+		if last := ret[len(ret)-1]; line <= last.line {
+			last.nodes = append(last.nodes, n)
+			continue
+		}
+		ret = append(ret, &sourceBlock{line: line, nodes: []*ast.Node{n}})
+	}
+	return
+}
+
+// neutralFormatOptions 返回在 options 基础上去掉所有样式的格式化选项，按该选项格式化的结果和按 options
+// 格式化的结果不同时说明块违反了样式。
+func neutralFormatOptions(options *render.Options) *render.Options {
+	ret := *options
+	ret.AutoSpace = false
+	ret.FixTermTypo = false
+	ret.ChinesePunct = false
+	ret.HeadingNumber = false
+	ret.FormatWrapWidth = 0
+	ret.FormatUnwrap = false
+	ret.FormatStyle = &render.FormatStyle{
+		HeadingStyle:         render.HeadingStylePreserve,
+		OrderedListNumbering: render.OrderedListNumberingPreserve,
+		Escape:               render.EscapePreserve,
+	}
+	return &ret
+}
@@ -0,0 +1,271 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/util"
+)
+
+// 链接问题类型。
+const (
+	LinkProblemAnchor     = "anchor"      // 文档内锚点没有对应的标题
+	LinkProblemLinkRef    = "link-ref"    // 链接引用没有定义
+	LinkProblemFootnote   = "footnote"    // 脚注引用没有定义
+	LinkProblemFile       = "file"        // 相对路径链接指向的文件不存在
+	LinkProblemFileAnchor = "file-anchor" // 链接到的 Markdown 文件中没有锚点对应的标题
+	LinkProblemBlockRef   = "block-ref"   // 内容块引用的块不存在
+)
+
+// LinkCheckOptions 描述了链接检查选项。
+type LinkCheckOptions struct {
+	Root                string // 本地根目录，不为空时检查相对路径链接指向的文件是否存在，以 / 开头的链接相对于该目录
+	Dir                 string // 当前文档所在目录（相对于 Root），不以 / 开头的链接相对于该目录
+	CheckLinkedHeadings bool   // 是否检查链接到的 Markdown 文件（.md、.markdown）中存在锚点对应的标题，比如 foo.md#bar
+	CheckBlockRefs      bool   // 是否检查内容块引用 ((id)) 的块在当前文档中存在
+}
+
+// LinkProblem 描述了链接检查发现的问题。
+type LinkProblem struct {
+	Kind    string    `json:"kind"`    // 问题类型
+	Line    int       `json:"line"`    // 所在行号，从 1 开始，无法确定时为 0
+	Path    string    `json:"path"`    // 节点路径
+	Dest    string    `json:"dest"`    // 链接目标
+	Message string    `json:"message"` // 问题描述
+	Node    *ast.Node `json:"-"`       // 有问题的节点
+}
+
+func (p *LinkProblem) String() string {
+	return strconv.Itoa(p.Line) + ": " + p.Message
+}
+
+// linkRefRegexp 用于在文本中查找没有解析为链接的 [text][label]、[text][] 和 [^label]。
+var linkRefRegexp = regexp.MustCompile(`\[(\^?)([^\[\]\n]+)\](?:\[([^\[\]\n]*)\])?`)
+
+// urlSchemeRegexp 用于判断链接是否带有协议，比如 https:、mailto:。
+var urlSchemeRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// CheckLinks 检查语法树 tree 中的链接、图片、脚注引用和内容块引用，返回发现的问题。
+//
+// 检查以下问题：
+//   - 文档内锚点 #foo 没有对应的标题 ID 或者块 ID
+//   - 链接引用 [text][foo]、[foo][] 没有定义（[foo] 也可能是普通文本，比如 arr[i]、[TODO]，所以不检查）
+//   - 脚注引用 [^foo] 没有定义
+//   - 相对路径链接指向的文件在 opts.Root 下不存在
+//   - 链接到的 Markdown 文件中没有锚点对应的标题（需要打开 opts.CheckLinkedHeadings）
+//   - 内容块引用的块在当前文档中不存在（需要打开 opts.CheckBlockRefs）
+func (lute *Lute) CheckLinks(tree *parse.Tree, opts *LinkCheckOptions) (ret []*LinkProblem) {
+	if nil == opts {
+		opts = &LinkCheckOptions{}
+	}
+
+	checker := &linkChecker{lute: lute, tree: tree, opts: opts, anchors: treeAnchors(tree), fileAnchors: map[string]map[string]bool{}}
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+
+		switch n.Type {
+		case ast.NodeLink, ast.NodeImage:
+			if 1 == n.LinkType {
+				// 链接引用定义本身不检查
+				return ast.WalkSkipChildren
+			}
+			if dest := n.ChildByType(ast.NodeLinkDest); nil != dest {
+				checker.checkDest(n, util.BytesToStr(dest.Tokens))
+			}
+		case ast.NodeText:
+			checker.checkText(n)
+		case ast.NodeBlockRef:
+			if opts.CheckBlockRefs {
+				if id := n.ChildByType(ast.NodeBlockRefID); nil != id && !checker.anchors[util.BytesToStr(id.Tokens)] {
+					checker.report(LinkProblemBlockRef, n, 0, util.BytesToStr(id.Tokens), "block ref (("+util.BytesToStr(id.Tokens)+")) points to a missing block")
+				}
+			}
+			return ast.WalkSkipChildren
+		case ast.NodeCodeSpan, ast.NodeCodeBlock, ast.NodeMathBlock, ast.NodeInlineMath, ast.NodeHTMLBlock, ast.NodeLinkRefDefBlock:
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	})
+	return checker.problems
+}
+
+type linkChecker struct {
+	lute        *Lute
+	tree        *parse.Tree
+	opts        *LinkCheckOptions
+	anchors     map[string]bool            // 当前文档中的标题 ID 和块 ID
+	fileAnchors map[string]map[string]bool // 已经解析过的 Markdown 文件中的标题 ID，键为文件绝对路径
+	problems    []*LinkProblem
+}
+
+func (c *linkChecker) report(kind string, n *ast.Node, offset int, dest, msg string) {
+	c.problems = append(c.problems, &LinkProblem{Kind: kind, Line: parse.NodeLine(n, offset), Path: parse.NodePath(n), Dest: dest, Message: msg, Node: n})
+}
+
+func (c *linkChecker) checkDest(n *ast.Node, dest string) {
+	if "" == dest || strings.HasPrefix(dest, "//") || urlSchemeRegexp.MatchString(dest) {
+		return
+	}
+
+	if strings.HasPrefix(dest, "#") {
+		if anchor := unescapeURL(dest[1:]); "" != anchor && !c.anchors[anchor] {
+			c.report(LinkProblemAnchor, n, 0, dest, "anchor "+dest+" does not match any heading")
+		}
+		return
+	}
+
+	if "" == c.opts.Root {
+		return
+	}
+
+	p, fragment := dest, ""
+	if i := strings.IndexAny(p, "?#"); 0 <= i {
+		if j := strings.IndexByte(p, '#'); 0 <= j {
+			fragment = unescapeURL(p[j+1:])
+		}
+		p = p[:i]
+	}
+	p = unescapeURL(p)
+	if "" == p {
+		return
+	}
+
+	root, err := filepath.Abs(c.opts.Root)
+	if nil != err {
+		return
+	}
+	var file string
+	if strings.HasPrefix(p, "/") {
+		file = filepath.Join(root, filepath.FromSlash(p))
+	} else {
+		file = filepath.Join(root, filepath.FromSlash(c.opts.Dir), filepath.FromSlash(p))
+	}
+	if file != root && !strings.HasPrefix(file, root+string(filepath.Separator)) {
+		c.report(LinkProblemFile, n, 0, dest, "link "+dest+" points outside the root directory")
+		return
+	}
+	info, err := os.Stat(file)
+	if nil != err {
+		c.report(LinkProblemFile, n, 0, dest, "link "+dest+" points to a missing file")
+		return
+	}
+
+	if !c.opts.CheckLinkedHeadings || "" == fragment || info.IsDir() {
+		return
+	}
+	if ext := strings.ToLower(filepath.Ext(file)); ".md" != ext && ".markdown" != ext {
+		return
+	}
+	if anchors := c.markdownFileAnchors(file); nil != anchors && !anchors[fragment] {
+		c.report(LinkProblemFileAnchor, n, 0, dest, "anchor #"+fragment+" does not match any heading in "+p)
+	}
+}
+
+// markdownFileAnchors 返回 Markdown 文件 file 中的标题 ID 和块 ID，读取失败时返回 nil。
+func (c *linkChecker) markdownFileAnchors(file string) map[string]bool {
+	if anchors, ok := c.fileAnchors[file]; ok {
+		return anchors
+	}
+
+	var anchors map[string]bool
+	if data, err := ioutil.ReadFile(file); nil == err {
+		if tree, err := c.lute.parse(filepath.Base(file), data); nil == err {
+			anchors = treeAnchors(tree)
+		}
+	}
+	c.fileAnchors[file] = anchors
+	return anchors
+}
+
+func (c *linkChecker) checkText(n *ast.Node) {
+	if nil == n.Parent || ast.NodeLinkText == n.Parent.Type || ast.NodeLink == n.Parent.Type {
+		return
+	}
+
+	text := util.BytesToStr(n.Tokens)
+	for _, m := range linkRefRegexp.FindAllStringSubmatchIndex(text, -1) {
+		if m[1] < len(text) && '(' == text[m[1]] {
+			// [foo](... 是没有闭合的内联链接，不是链接引用
+			continue
+		}
+
+		if m[3] > m[2] {
+			label := text[m[4]:m[5]]
+			if c.lute.ParseOptions.Footnotes {
+				c.report(LinkProblemFootnote, n, m[0], "^"+label, "footnote [^"+label+"] is not defined")
+			}
+			continue
+		}
+
+		if 0 > m[6] {
+			// 只有 [foo] 时无法区分是链接引用还是普通文本
+			continue
+		}
+		if 0 < m[0] && isLinkRefWordChar(text[m[0]-1]) {
+			// arr[i][j] 这样紧跟在单词后的方括号不是链接引用
+			continue
+		}
+
+		label := text[m[4]:m[5]]
+		if m[7] > m[6] {
+			label = text[m[6]:m[7]]
+		}
+		if "" == strings.TrimSpace(label) {
+			continue
+		}
+		c.report(LinkProblemLinkRef, n, m[0], label, "link reference ["+label+"] is not defined")
+	}
+}
+
+func isLinkRefWordChar(c byte) bool {
+	return ('a' <= c && 'z' >= c) || ('A' <= c && 'Z' >= c) || ('0' <= c && '9' >= c) || '_' == c || ']' == c || ')' == c
+}
+
+// treeAnchors 返回语法树 tree 中所有标题的 ID 和带有 ID 的块的 ID。
+func treeAnchors(tree *parse.Tree) (ret map[string]bool) {
+	ret = map[string]bool{}
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		if ast.NodeHeading == n.Type {
+			ret[tree.HeadingID(n)] = true
+		}
+		if n.IsBlock() {
+			if "" != n.ID {
+				ret[n.ID] = true
+			}
+			if id := n.IALAttr("id"); "" != id {
+				ret[id] = true
+			}
+		}
+		return ast.WalkContinue
+	})
+	return
+}
+
+func unescapeURL(s string) string {
+	if ret, err := url.PathUnescape(s); nil == err {
+		return ret
+	}
+	return s
+}
@@ -0,0 +1,229 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/util"
+)
+
+// 链接引用 label 生成方式。
+const (
+	LinkRefLabelNumber = "number" // 按链接出现的顺序编号，比如 1、2、3
+	LinkRefLabelURL    = "url"    // 由链接地址生成，比如 https://b3log.org/lute 生成 b3log-org-lute
+)
+
+// bracketTextRegexp 用于查找文本中的 [label]。
+var bracketTextRegexp = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+// ReferenceLinks 返回将内联链接和图片转换为链接引用的转换器，labelStyle 指定新 label 的生成方式。
+//
+// 地址和标题都相同的链接共用一个 label，文档中已有的链接引用定义会被复用。转换后所有链接引用定义都移动到文档末尾。
+func ReferenceLinks(labelStyle string) Transformer {
+	return func(tree *parse.Tree) error {
+		refs := &linkRefs{style: labelStyle, used: map[string]bool{}, labels: map[string]string{}}
+		defined := map[string]bool{}
+		var defBlocks, defs, links []*ast.Node
+		ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.WalkContinue
+			}
+
+			switch n.Type {
+			case ast.NodeText:
+				// 文本中的 [1] 这样的方括号在生成同名的链接引用定义后会被解析为链接，所以不能使用这些 label
+				for _, m := range bracketTextRegexp.FindAllSubmatch(n.Tokens, -1) {
+					refs.used[normalizeLinkRefLabel(util.BytesToStr(m[1]))] = true
+				}
+			case ast.NodeLinkRefDefBlock:
+				defBlocks = append(defBlocks, n)
+			case ast.NodeLinkRefDef:
+				label := util.BytesToStr(n.Tokens)
+				if defined[normalizeLinkRefLabel(label)] {
+					// 重复定义的 label 只有第一个生效
+					return ast.WalkSkipChildren
+				}
+				defined[normalizeLinkRefLabel(label)] = true
+				refs.used[normalizeLinkRefLabel(label)] = true
+				if key := linkKey(n.FirstChild); "" == refs.labels[key] {
+					refs.labels[key] = label
+				}
+				defs = append(defs, n)
+				return ast.WalkSkipChildren
+			case ast.NodeLink, ast.NodeImage:
+				if 0 == n.LinkType {
+					if dest := n.ChildByType(ast.NodeLinkDest); nil != dest && 0 < len(dest.Tokens) {
+						links = append(links, n)
+					}
+				}
+			}
+			return ast.WalkContinue
+		})
+
+		for _, link := range links {
+			key := linkKey(link)
+			label := refs.labels[key]
+			if "" == label {
+				label = refs.newLabel(util.BytesToStr(link.ChildByType(ast.NodeLinkDest).Tokens))
+				refs.labels[key] = label
+				defs = append(defs, newLinkRefDef(label, link))
+			}
+			link.LinkType = 3
+			link.LinkRefLabel = []byte(label)
+		}
+
+		for _, defBlock := range defBlocks {
+			defBlock.Unlink()
+		}
+		if 0 < len(defs) {
+			defBlock := &ast.Node{Type: ast.NodeLinkRefDefBlock}
+			for _, def := range defs {
+				def.Unlink()
+				defBlock.AppendChild(def)
+			}
+			tree.Root.AppendChild(defBlock)
+		}
+		return nil
+	}
+}
+
+// InlineLinks 返回将链接引用 [text][label] 转换为内联链接并移除所有链接引用定义的转换器。
+func InlineLinks() Transformer {
+	return func(tree *parse.Tree) error {
+		var defBlocks []*ast.Node
+		ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.WalkContinue
+			}
+
+			switch n.Type {
+			case ast.NodeLinkRefDefBlock:
+				defBlocks = append(defBlocks, n)
+				return ast.WalkSkipChildren
+			case ast.NodeLink, ast.NodeImage:
+				if 3 == n.LinkType {
+					// 解析链接引用时已经将定义中的地址和标题放到了链接下
+					n.LinkType = 0
+					n.LinkRefLabel = nil
+					if title := n.ChildByType(ast.NodeLinkTitle); nil != title && (nil == title.Previous || ast.NodeLinkSpace != title.Previous.Type) {
+						title.InsertBefore(&ast.Node{Type: ast.NodeLinkSpace, Tokens: []byte(" ")})
+					}
+				}
+			}
+			return ast.WalkContinue
+		})
+
+		for _, defBlock := range defBlocks {
+			defBlock.Unlink()
+		}
+		return nil
+	}
+}
+
+// linkRefs 记录转换过程中已经使用的 label。
+type linkRefs struct {
+	style  string
+	used   map[string]bool   // 已经使用的 label（规范化后）
+	labels map[string]string // 链接地址和标题到 label 的映射
+	num    int               // 按顺序编号时的当前编号
+}
+
+// newLabel 为链接地址 dest 生成一个没有使用过的 label。
+func (refs *linkRefs) newLabel(dest string) (ret string) {
+	if LinkRefLabelURL == refs.style {
+		base := urlLinkRefLabel(dest)
+		ret = base
+		for i := 2; refs.used[normalizeLinkRefLabel(ret)]; i++ {
+			ret = base + "-" + strconv.Itoa(i)
+		}
+	} else {
+		for {
+			refs.num++
+			if ret = strconv.Itoa(refs.num); !refs.used[ret] {
+				break
+			}
+		}
+	}
+	refs.used[normalizeLinkRefLabel(ret)] = true
+	return
+}
+
+// urlLinkRefLabel 由链接地址 dest 生成 label：去掉协议和 www. 前缀，连续的非字母数字字符替换为一个连字符。
+func urlLinkRefLabel(dest string) string {
+	if i := strings.Index(dest, "://"); 0 < i {
+		dest = dest[i+3:]
+	}
+	dest = strings.TrimPrefix(dest, "www.")
+
+	buf := &strings.Builder{}
+	hyphen := false
+	for _, r := range strings.ToLower(dest) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && 0 < buf.Len() {
+				buf.WriteByte('-')
+			}
+			buf.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+
+	ret := []rune(buf.String())
+	if 48 < len(ret) {
+		ret = []rune(strings.TrimRight(string(ret[:48]), "-"))
+	}
+	if 1 > len(ret) {
+		return "link"
+	}
+	return string(ret)
+}
+
+// normalizeLinkRefLabel 按照 CommonMark 规范化 label：忽略大小写，合并连续的空白。
+func normalizeLinkRefLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// linkKey 返回链接 link 的地址和标题，用于判断链接是否可以共用一个 label。
+func linkKey(link *ast.Node) (ret string) {
+	if dest := link.ChildByType(ast.NodeLinkDest); nil != dest {
+		ret = util.BytesToStr(dest.Tokens)
+	}
+	if title := link.ChildByType(ast.NodeLinkTitle); nil != title {
+		ret += "\n" + util.BytesToStr(title.Tokens)
+	}
+	return
+}
+
+// newLinkRefDef 使用链接 link 的地址和标题构造 label 的链接引用定义。
+func newLinkRefDef(label string, link *ast.Node) *ast.Node {
+	defLink := &ast.Node{Type: ast.NodeLink, LinkType: 1, LinkRefLabel: []byte(label)}
+	defLink.AppendChild(&ast.Node{Type: ast.NodeOpenBracket, Tokens: []byte("[")})
+	defLink.AppendChild(&ast.Node{Type: ast.NodeLinkText, Tokens: []byte(label)})
+	defLink.AppendChild(&ast.Node{Type: ast.NodeCloseBracket, Tokens: []byte("]")})
+	defLink.AppendChild(&ast.Node{Type: ast.NodeOpenParen, Tokens: []byte("(")})
+	defLink.AppendChild(&ast.Node{Type: ast.NodeLinkDest, Tokens: link.ChildByType(ast.NodeLinkDest).Tokens})
+	if title := link.ChildByType(ast.NodeLinkTitle); nil != title {
+		defLink.AppendChild(&ast.Node{Type: ast.NodeLinkSpace, Tokens: []byte(" ")})
+		defLink.AppendChild(&ast.Node{Type: ast.NodeLinkTitle, Tokens: title.Tokens})
+	}
+	defLink.AppendChild(&ast.Node{Type: ast.NodeCloseParen, Tokens: []byte(")")})
+
+	ret := &ast.Node{Type: ast.NodeLinkRefDef, Tokens: []byte(label)}
+	ret.AppendChild(defLink)
+	return ret
+}
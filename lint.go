@@ -0,0 +1,434 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+	"github.com/sunlightcs/lute/util"
+)
+
+// 检查规则名称。
+const (
+	LintHeadingIncrement = "heading-increment"    // 标题级别每次只能增加一级
+	LintSingleH1         = "single-h1"            // 文档中只能有一个一级标题
+	LintTrailingSpaces   = "no-trailing-spaces"   // 行尾不能有空格，硬换行使用的两个空格除外
+	LintBareURL          = "no-bare-urls"         // 链接地址不能直接写在文本中
+	LintListMarker       = "list-marker-style"    // 无序列表标记符要一致
+	LintFenceLanguage    = "fenced-code-language" // 围栏代码块要指定语言
+	LintLineLength       = "line-length"          // 行不能过长
+	LintImageAlt         = "image-alt-text"       // 图片要有替代文本
+	LintCJKSpacing       = "cjk-latin-spacing"    // 中西文之间要有空格
+	LintChinesePunct     = "chinese-punctuation"  // 中文标点要规范，比如中文之间使用全角标点、不重复使用标点
+	LintTermTypo         = "term-typo"            // 术语拼写要正确，术语字典见 Lute.LoadTerms
+)
+
+// LintOptions 描述了检查选项。
+type LintOptions struct {
+	// Rules 设置规则开关，键为规则名称，没有设置的规则默认打开。
+	Rules map[string]bool
+	// LineLength 设置行的最大宽度，中日韩字符宽度计为 2，默认为 80。超出部分没有空白（比如长链接）的行不检查。
+	LineLength int
+	// ListMarker 设置无序列表标记符，支持 "*"、"-"、"+"，默认为 "consistent"，即和文档中第一个无序列表一致。
+	ListMarker string
+}
+
+// NewLintOptions 创建一个默认的检查选项。
+func NewLintOptions() *LintOptions {
+	return &LintOptions{Rules: map[string]bool{}, LineLength: 80, ListMarker: "consistent"}
+}
+
+// Enabled 判断规则 rule 是否打开。
+func (opts *LintOptions) Enabled(rule string) bool {
+	enabled, ok := opts.Rules[rule]
+	return !ok || enabled
+}
+
+// Diagnostic 描述了检查发现的问题。
+type Diagnostic struct {
+	Rule    string    `json:"rule"`    // 规则名称
+	Line    int       `json:"line"`    // 行号，从 1 开始，无法确定时为 0
+	Column  int       `json:"column"`  // 列号，从 1 开始，无法确定时为 0
+	Message string    `json:"message"` // 问题描述
+	Fixable bool      `json:"fixable"` // 是否可以通过 Fix 自动修复
+	Node    *ast.Node `json:"-"`       // 有问题的节点，按行检查的规则为 nil
+}
+
+func (d *Diagnostic) String() string {
+	ret := strconv.Itoa(d.Line)
+	if 0 < d.Column {
+		ret += ":" + strconv.Itoa(d.Column)
+	}
+	return ret + ": " + d.Rule + ": " + d.Message
+}
+
+// lintRule 描述了一条检查规则。
+type lintRule struct {
+	name    string
+	fixable bool
+	check   func(l *linter)
+	fix     func(l *linter) // 修复语法树或者渲染选项，为 nil 时依靠格式化本身修复
+}
+
+var lintRules = []*lintRule{
+	{name: LintHeadingIncrement, check: checkHeadingIncrement},
+	{name: LintSingleH1, check: checkSingleH1},
+	{name: LintTrailingSpaces, fixable: true, check: checkTrailingSpaces},
+	{name: LintBareURL, fixable: true, check: checkBareURL},
+	{name: LintListMarker, fixable: true, check: checkListMarker, fix: fixListMarker},
+	{name: LintFenceLanguage, check: checkFenceLanguage},
+	{name: LintLineLength, check: checkLineLength},
+	{name: LintImageAlt, check: checkImageAlt},
+	{name: LintCJKSpacing, fixable: true, check: checkCJKSpacing, fix: fixCJKSpacing},
+	{name: LintChinesePunct, fixable: true, check: checkChinesePunct, fix: fixChinesePunct},
+	{name: LintTermTypo, fixable: true, check: checkTermTypo, fix: fixTermTypo},
+}
+
+// Lint 按照 lute.LintOptions 检查 markdown，返回按行号排序的问题列表。
+func (lute *Lute) Lint(markdown string) (ret []*Diagnostic, err error) {
+	l, err := lute.newLinter(markdown)
+	if nil != err {
+		return
+	}
+
+	for _, rule := range lintRules {
+		if lute.LintOptions.Enabled(rule.name) {
+			l.rule = rule
+			rule.check(l)
+		}
+	}
+	sort.SliceStable(l.diagnostics, func(i, j int) bool { return l.diagnostics[i].Line < l.diagnostics[j].Line })
+	ret = l.diagnostics
+	return
+}
+
+// Fix 按照 lute.LintOptions 修复 markdown 中可以自动修复的问题，修复后的文本由格式化渲染器生成。
+func (lute *Lute) Fix(markdown string) (fixed string) {
+	l, err := lute.newLinter(markdown)
+	if nil != err {
+		return err.Error()
+	}
+
+	for _, rule := range lintRules {
+		if rule.fixable && nil != rule.fix && lute.LintOptions.Enabled(rule.name) {
+			rule.fix(l)
+		}
+	}
+	renderer := render.NewFormatRenderer(l.tree, l.renderOptions)
+	return util.BytesToStr(renderer.Render())
+}
+
+// linter 保存一次检查或者修复过程中的状态。
+type linter struct {
+	lute          *Lute
+	opts          *LintOptions
+	tree          *parse.Tree
+	lines         []string        // 源码行
+	skipLines     map[int]bool    // 代码块、数学公式块、HTML 块等不按行检查的行号
+	tableLines    map[int]bool    // 表格所在的行号
+	renderOptions *render.Options // 修复时使用的渲染选项
+	rule          *lintRule       // 正在检查的规则
+	diagnostics   []*Diagnostic
+}
+
+func (lute *Lute) newLinter(markdown string) (ret *linter, err error) {
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		return
+	}
+
+	renderOptions := *lute.RenderOptions
+	renderOptions.AutoSpace = false
+	renderOptions.ChinesePunct = false
+	renderOptions.FixTermTypo = false
+	ret = &linter{lute: lute, opts: lute.LintOptions, tree: tree, renderOptions: &renderOptions,
+		lines: strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n"), skipLines: map[int]bool{}, tableLines: map[int]bool{}}
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || !n.IsBlock() {
+			return ast.WalkContinue
+		}
+		switch n.Type {
+		case ast.NodeCodeBlock, ast.NodeMathBlock, ast.NodeHTMLBlock, ast.NodeYamlFrontMatter:
+			ret.markLines(n, ret.skipLines)
+			return ast.WalkSkipChildren
+		case ast.NodeTable:
+			ret.markLines(n, ret.tableLines)
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	})
+	return
+}
+
+// markLines 在 lines 中标记块 block 占用的行，即从块的起始行到下一个块的起始行之前。
+func (l *linter) markLines(block *ast.Node, lines map[int]bool) {
+	if 1 > block.SourceLine {
+		return
+	}
+
+	end := len(l.lines)
+	for n := block; nil != n; n = n.Parent {
+		if nil != n.Next && 0 < n.Next.SourceLine {
+			end = n.Next.SourceLine - 1
+			break
+		}
+	}
+	for i := block.SourceLine; i <= end; i++ {
+		lines[i] = true
+	}
+}
+
+func (l *linter) report(n *ast.Node, line, column int, msg string) {
+	if nil != n && 1 > line {
+		line = parse.NodeLine(n, 0)
+	}
+	l.diagnostics = append(l.diagnostics, &Diagnostic{Rule: l.rule.name, Line: line, Column: column, Message: msg, Fixable: l.rule.fixable, Node: n})
+}
+
+// walkNodes 按文档顺序遍历语法树中 types 类型的节点。
+func (l *linter) walkNodes(walker func(n *ast.Node), types ...ast.NodeType) {
+	ast.Walk(l.tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		for _, typ := range types {
+			if typ == n.Type {
+				walker(n)
+				break
+			}
+		}
+		return ast.WalkContinue
+	})
+}
+
+func checkHeadingIncrement(l *linter) {
+	level := 0
+	l.walkNodes(func(n *ast.Node) {
+		if 0 < level && n.HeadingLevel > level+1 {
+			l.report(n, 0, 0, "heading level jumps from h"+strconv.Itoa(level)+" to h"+strconv.Itoa(n.HeadingLevel))
+		}
+		level = n.HeadingLevel
+	}, ast.NodeHeading)
+}
+
+func checkSingleH1(l *linter) {
+	h1s := 0
+	l.walkNodes(func(n *ast.Node) {
+		if 1 != n.HeadingLevel {
+			return
+		}
+		if h1s++; 1 < h1s {
+			l.report(n, 0, 0, "multiple top-level headings in the same document")
+		}
+	}, ast.NodeHeading)
+}
+
+func checkTrailingSpaces(l *linter) {
+	for i, line := range l.lines {
+		if l.skipLines[i+1] {
+			continue
+		}
+		trimmed := strings.TrimRight(line, " \t")
+		spaces := len(line) - len(trimmed)
+		if 0 == spaces || "" == trimmed {
+			continue
+		}
+		if 2 == spaces && "  " == line[len(trimmed):] && i+1 < len(l.lines) && "" != strings.TrimSpace(l.lines[i+1]) {
+			// 两个空格是硬换行
+			continue
+		}
+		l.report(nil, i+1, utf8.RuneCountInString(trimmed)+1, "trailing spaces")
+	}
+}
+
+func checkBareURL(l *linter) {
+	l.walkNodes(func(n *ast.Node) {
+		if 2 != n.LinkType {
+			return
+		}
+		text := n.ChildByType(ast.NodeLinkText)
+		if nil == text {
+			return
+		}
+		url := util.BytesToStr(text.Tokens)
+		if line := parse.NodeLine(n, 0); 0 < line && line <= len(l.lines) && strings.Contains(l.lines[line-1], "<"+url+">") {
+			// <https://b3log.org> 形式的自动链接
+			return
+		}
+		l.report(n, 0, 0, "bare URL "+url)
+	}, ast.NodeLink)
+}
+
+// expectedListMarker 返回文档中无序列表应该使用的标记符，没有无序列表时返回 0。
+func (l *linter) expectedListMarker() (ret byte) {
+	if m := l.opts.ListMarker; "*" == m || "-" == m || "+" == m {
+		return m[0]
+	}
+	l.walkNodes(func(n *ast.Node) {
+		if 0 == ret && 0 != n.BulletChar {
+			ret = n.BulletChar
+		}
+	}, ast.NodeList)
+	return
+}
+
+func checkListMarker(l *linter) {
+	expected := l.expectedListMarker()
+	l.walkNodes(func(n *ast.Node) {
+		if 0 != n.BulletChar && expected != n.BulletChar {
+			l.report(n, 0, 0, "list marker '"+string(n.BulletChar)+"' differs from '"+string(expected)+"'")
+		}
+	}, ast.NodeList)
+}
+
+func fixListMarker(l *linter) {
+	expected := l.expectedListMarker()
+	var lists []*ast.Node
+	l.walkNodes(func(n *ast.Node) {
+		if 0 != n.BulletChar && expected != n.BulletChar {
+			lists = append(lists, n)
+		}
+	}, ast.NodeList)
+
+	for _, list := range lists {
+		list.BulletChar = expected
+		list.Marker = []byte{expected}
+		for item := list.FirstChild; nil != item; item = item.Next {
+			if nil != item.ListData && 0 != item.BulletChar {
+				item.BulletChar = expected
+				item.Marker = []byte{expected}
+				item.Tokens = []byte{expected}
+			}
+		}
+
+		// 标记符不同的相邻列表原本是两个列表，统一标记符后合并，否则格式化后重新解析会变成一个松散列表
+		if prev := list.Previous; nil != prev && ast.NodeList == prev.Type && expected == prev.BulletChar {
+			for item := list.FirstChild; nil != item; {
+				next := item.Next
+				prev.AppendChild(item)
+				item = next
+			}
+			prev.Tight = prev.Tight && list.Tight
+			list.Unlink()
+		}
+	}
+}
+
+func checkFenceLanguage(l *linter) {
+	l.walkNodes(func(n *ast.Node) {
+		if n.IsFencedCodeBlock && "" == strings.TrimSpace(util.BytesToStr(n.CodeBlockInfo)) {
+			l.report(n, 0, 0, "fenced code block has no language")
+		}
+	}, ast.NodeCodeBlock)
+}
+
+func checkLineLength(l *linter) {
+	for i, line := range l.lines {
+		if l.skipLines[i+1] || l.tableLines[i+1] {
+			continue
+		}
+
+		width, column := 0, 0
+		for j, r := range line {
+			if width += render.RuneWidth(r); width > l.opts.LineLength && 0 == column {
+				column = utf8.RuneCountInString(line[:j]) + 1
+				if !strings.ContainsAny(line[j:], " \t") {
+					// 超出部分没有空白，比如长链接，无法折行
+					column = -1
+				}
+			}
+		}
+		if 0 < column {
+			l.report(nil, i+1, column, "line width "+strconv.Itoa(width)+" exceeds "+strconv.Itoa(l.opts.LineLength))
+		}
+	}
+}
+
+func checkImageAlt(l *linter) {
+	l.walkNodes(func(n *ast.Node) {
+		if alt := n.ChildByType(ast.NodeLinkText); nil == alt || "" == strings.TrimSpace(util.BytesToStr(alt.Tokens)) {
+			l.report(n, 0, 0, "image has no alt text")
+		}
+	}, ast.NodeImage)
+}
+
+func checkCJKSpacing(l *linter) {
+	l.walkNodes(func(n *ast.Node) {
+		if nil != n.Parent && (ast.NodeLink == n.Parent.Type || ast.NodeLinkText == n.Parent.Type) && 2 == n.Parent.LinkType {
+			return
+		}
+		if text := util.BytesToStr(n.Tokens); render.Space0(text) != text {
+			l.report(n, 0, 0, "missing space between CJK and Latin characters")
+		}
+	}, ast.NodeText)
+}
+
+func fixCJKSpacing(l *linter) {
+	l.renderOptions.AutoSpace = true
+}
+
+func checkChinesePunct(l *linter) {
+	l.walkNodes(func(n *ast.Node) {
+		for _, change := range render.ChinesePunctChanges(n.Tokens) {
+			l.report(n, parse.NodeLine(n, change.Offset), l.textColumn(n, change.Offset),
+				"punctuation "+strconv.Quote(change.From)+" should be "+strconv.Quote(change.To))
+		}
+	}, ast.NodeText)
+}
+
+func fixChinesePunct(l *linter) {
+	l.renderOptions.ChinesePunct = true
+}
+
+func checkTermTypo(l *linter) {
+	dicts := render.TermDicts(l.renderOptions)
+	l.walkNodes(func(n *ast.Node) {
+		if nil != n.Parent && (ast.NodeLink == n.Parent.Type || ast.NodeLinkText == n.Parent.Type) && 2 == n.Parent.LinkType {
+			return
+		}
+		for _, correction := range render.TermCorrections(n.Tokens, dicts) {
+			l.report(n, parse.NodeLine(n, correction.Offset), l.textColumn(n, correction.Offset),
+				"term "+strconv.Quote(correction.From)+" should be "+strconv.Quote(correction.To))
+		}
+	}, ast.NodeText)
+}
+
+func fixTermTypo(l *linter) {
+	l.renderOptions.FixTermTypo = true
+}
+
+// textColumn 返回文本节点 text 中字节偏移 offset 处在源码行中的列号，在源码行中找不到该文本（比如包含转义）时返回 0。
+func (l *linter) textColumn(text *ast.Node, offset int) int {
+	line := parse.NodeLine(text, offset)
+	if 1 > line || line > len(l.lines) {
+		return 0
+	}
+
+	tokens := util.BytesToStr(text.Tokens)
+	start := strings.LastIndexByte(tokens[:offset], '\n') + 1
+	end := strings.IndexByte(tokens[offset:], '\n')
+	if 0 > end {
+		end = len(tokens)
+	} else {
+		end += offset
+	}
+	index := strings.Index(l.lines[line-1], tokens[start:end])
+	if 0 > index {
+		return 0
+	}
+	return utf8.RuneCountInString(l.lines[line-1][:index]) + utf8.RuneCountInString(tokens[start:offset]) + 1
+}
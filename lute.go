@@ -39,6 +39,13 @@ type Lute struct {
 	Md2VditorIRDOMRendererFuncs        map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorIRDOM 渲染器函数
 	Md2VditorIRBlockDOMRendererFuncs   map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorIRBlockDOM 渲染器函数
 	Md2VditorSVDOMRendererFuncs        map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorSVDOM 渲染器函数
+
+	BlockResolver render.BlockResolver // 内容块解析器，设置后渲染时会使用被引用内容块填充引用锚文本并展开内容块嵌入
+	QueryExecutor render.QueryExecutor // 内容块查询执行器，设置后渲染时会执行内容块查询嵌入并渲染查询结果
+
+	Transformers []Transformer // 语法树转换器，按顺序在解析之后、渲染之前执行
+
+	LintOptions *LintOptions // 检查选项
 }
 
 // New 创建一个新的 Lute 引擎。
@@ -57,7 +64,7 @@ type Lute struct {
 //  * 修正术语拼写
 //  * 标题自定义 ID
 func New(opts ...ParseOption) (ret *Lute) {
-	ret = &Lute{ParseOptions: parse.NewOptions(), RenderOptions: render.NewOptions()}
+	ret = &Lute{ParseOptions: parse.NewOptions(), RenderOptions: render.NewOptions(), LintOptions: NewLintOptions()}
 	for _, opt := range opts {
 		opt(ret)
 	}
@@ -77,8 +84,14 @@ func New(opts ...ParseOption) (ret *Lute) {
 
 // Markdown 将 markdown 文本字节数组处理为相应的 html 字节数组。name 参数仅用于标识文本，比如可传入 id 或者标题，也可以传入 ""。
 func (lute *Lute) Markdown(name string, markdown []byte) (html []byte) {
-	tree := parse.Parse(name, markdown, lute.ParseOptions)
+	tree, err := lute.parse(name, markdown)
+	if nil != err {
+		html = []byte(err.Error())
+		return
+	}
 	renderer := render.NewHtmlRenderer(tree, lute.RenderOptions)
+	renderer.BlockResolver = lute.BlockResolver
+	renderer.QueryExecutor = lute.QueryExecutor
 	for nodeType, rendererFunc := range lute.Md2HTMLRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
 	}
@@ -95,7 +108,11 @@ func (lute *Lute) MarkdownStr(name, markdown string) (html string) {
 
 // Format 将 markdown 文本字节数组进行格式化。
 func (lute *Lute) Format(name string, markdown []byte) (formatted []byte) {
-	tree := parse.Parse(name, markdown, lute.ParseOptions)
+	tree, err := lute.parse(name, markdown)
+	if nil != err {
+		formatted = []byte(err.Error())
+		return
+	}
 	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
 	formatted = renderer.Render()
 	return
@@ -110,7 +127,11 @@ func (lute *Lute) FormatStr(name, markdown string) (formatted string) {
 
 // TextBundle 将 markdown 文本字节数组进行 TextBundle 处理。
 func (lute *Lute) TextBundle(name string, markdown []byte, linkPrefixes []string) (textbundle []byte, originalLinks []string) {
-	tree := parse.Parse(name, markdown, lute.ParseOptions)
+	tree, err := lute.parse(name, markdown)
+	if nil != err {
+		textbundle = []byte(err.Error())
+		return
+	}
 	renderer := render.NewTextBundleRenderer(tree, linkPrefixes, lute.RenderOptions)
 	textbundle, originalLinks = renderer.Render()
 	return
@@ -134,7 +155,11 @@ func (lute *Lute) HTML2Text(dom string) string {
 
 // RenderJSON 用于渲染 JSON 格式数据。
 func (lute *Lute) RenderJSON(markdown string) (json string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		json = err.Error()
+		return
+	}
 	renderer := render.NewJSONRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
 	json = string(output)
@@ -204,6 +229,20 @@ func (lute *Lute) PutTerms(termMap map[string]string) {
 	}
 }
 
+// LoadTerms 从术语字典文件 paths 加载术语，每个文件作为一个字典（比如按领域划分的字典包），后加载的字典优先。
+//
+// 文件中每行一个 term=Correct，比如 vscode=Visual Studio Code，忽略空行和 # 开头的注释行。
+func (lute *Lute) LoadTerms(paths ...string) error {
+	for _, path := range paths {
+		dict, err := render.LoadTermDict(path)
+		if nil != err {
+			return err
+		}
+		lute.RenderOptions.TermDicts = append(lute.RenderOptions.TermDicts, dict)
+	}
+	return nil
+}
+
 // FormatNode 使用指定的 options 格式化 node，返回格式化后的 Markdown 文本。
 func FormatNode(node *ast.Node, parseOptions *parse.Options, renderOptions *render.Options) string {
 	root := &ast.Node{Type: ast.NodeDocument}
@@ -221,6 +260,8 @@ func FormatNode(node *ast.Node, parseOptions *parse.Options, renderOptions *rend
 // Tree2HTML 使用指定的 options 渲染 tree 为标准 HTML。
 func (lute *Lute) Tree2HTML(tree *parse.Tree, options *render.Options) string {
 	renderer := render.NewHtmlRenderer(tree, options)
+	renderer.BlockResolver = lute.BlockResolver
+	renderer.QueryExecutor = lute.QueryExecutor
 	output := renderer.Render()
 	return string(output)
 }
@@ -288,6 +329,66 @@ func (lute *Lute) SetHeadingID(b bool) {
 	lute.RenderOptions.HeadingID = b
 }
 
+func (lute *Lute) SetHeadingIDSlug(slug string) {
+	lute.ParseOptions.HeadingIDSlug = slug
+}
+
+func (lute *Lute) SetHeadingIDSlugFunc(slug func(text string) string) {
+	lute.ParseOptions.HeadingIDSlugFunc = slug
+}
+
+func (lute *Lute) SetToCMinLevel(level int) {
+	lute.RenderOptions.ToCMinLevel = level
+}
+
+func (lute *Lute) SetToCMaxLevel(level int) {
+	lute.RenderOptions.ToCMaxLevel = level
+}
+
+func (lute *Lute) SetToCOrdered(b bool) {
+	lute.RenderOptions.ToCOrdered = b
+}
+
+func (lute *Lute) SetToCTitle(title string) {
+	lute.RenderOptions.ToCTitle = title
+}
+
+func (lute *Lute) SetToCExcludeIALAttr(name string) {
+	lute.RenderOptions.ToCExcludeIALAttr = name
+}
+
+func (lute *Lute) SetToCNumbered(b bool) {
+	lute.RenderOptions.ToCNumbered = b
+}
+
+func (lute *Lute) SetHeadingNumber(b bool) {
+	lute.RenderOptions.HeadingNumber = b
+}
+
+func (lute *Lute) SetHeadingNumberStartLevel(level int) {
+	lute.RenderOptions.HeadingNumberStartLevel = level
+}
+
+func (lute *Lute) SetHeadingNumberStyle(style string) {
+	lute.RenderOptions.HeadingNumberStyle = style
+}
+
+func (lute *Lute) SetHeadingNumberExcludeIALAttr(name string) {
+	lute.RenderOptions.HeadingNumberExcludeIALAttr = name
+}
+
+func (lute *Lute) SetFormatWrapWidth(width int) {
+	lute.RenderOptions.FormatWrapWidth = width
+}
+
+func (lute *Lute) SetFormatUnwrap(b bool) {
+	lute.RenderOptions.FormatUnwrap = b
+}
+
+func (lute *Lute) SetFormatStyle(style *render.FormatStyle) {
+	lute.RenderOptions.FormatStyle = style
+}
+
 func (lute *Lute) SetAutoSpace(b bool) {
 	lute.RenderOptions.AutoSpace = b
 }
@@ -296,6 +397,10 @@ func (lute *Lute) SetFixTermTypo(b bool) {
 	lute.RenderOptions.FixTermTypo = b
 }
 
+func (lute *Lute) SetChinesePunct(b bool) {
+	lute.RenderOptions.ChinesePunct = b
+}
+
 func (lute *Lute) SetEmoji(b bool) {
 	lute.ParseOptions.Emoji = b
 }
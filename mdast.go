@@ -0,0 +1,57 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"encoding/json"
+
+	"github.com/sunlightcs/lute/mdast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+	"github.com/sunlightcs/lute/util"
+)
+
+// RenderMdast 将 markdown 解析后渲染为 mdast（https://github.com/syntax-tree/mdast）JSON。
+func (lute *Lute) RenderMdast(markdown string) (json string) {
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		json = err.Error()
+		return
+	}
+	return lute.Tree2Mdast(tree)
+}
+
+// Tree2Mdast 将语法树 tree 渲染为 mdast JSON。
+func (lute *Lute) Tree2Mdast(tree *parse.Tree) string {
+	data, _ := json.Marshal(mdast.FromTree(tree))
+	return string(data)
+}
+
+// Mdast2Tree 将 mdast JSON 转换为语法树。
+func (lute *Lute) Mdast2Tree(mdastJSON string) (tree *parse.Tree, err error) {
+	root, err := mdast.Parse([]byte(mdastJSON))
+	if nil != err {
+		return
+	}
+	tree = mdast.ToTree(root, lute.ParseOptions)
+	return
+}
+
+// Mdast2Md 将 mdast JSON 转换为格式化后的 Markdown。
+func (lute *Lute) Mdast2Md(mdastJSON string) (markdown string, err error) {
+	tree, err := lute.Mdast2Tree(mdastJSON)
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
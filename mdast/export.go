@@ -0,0 +1,252 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package mdast
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+// FromTree 将 Lute 语法树 tree 转换为 mdast 根节点。
+func FromTree(tree *parse.Tree) *Node {
+	return fromNode(tree.Root)[0]
+}
+
+// fromNode 将 n 转换为 mdast 节点，标记符等没有对应 mdast 节点的 Lute 节点返回空，脚注定义块等容器节点返回其子节点。
+func fromNode(n *ast.Node) (ret []*Node) {
+	var node *Node
+	switch n.Type {
+	case ast.NodeDocument:
+		node = &Node{Type: "root"}
+	case ast.NodeParagraph:
+		node = &Node{Type: "paragraph"}
+	case ast.NodeHeading:
+		node = &Node{Type: "heading", Depth: n.HeadingLevel}
+	case ast.NodeThematicBreak:
+		node = &Node{Type: "thematicBreak"}
+	case ast.NodeBlockquote:
+		node = &Node{Type: "blockquote"}
+	case ast.NodeList:
+		node = &Node{Type: "list", Ordered: boolean(1 == n.ListData.Typ), Spread: boolean(!n.ListData.Tight)}
+		if 1 == n.ListData.Typ {
+			start := n.ListData.Start
+			node.Start = &start
+		}
+	case ast.NodeListItem:
+		node = &Node{Type: "listItem", Spread: boolean(!n.Parent.ListData.Tight)}
+		if 3 == n.ListData.Typ {
+			node.Checked = boolean(n.ListData.Checked)
+		}
+	case ast.NodeHTMLBlock:
+		node = &Node{Type: "html", Value: str(string(n.Tokens))}
+	case ast.NodeInlineHTML:
+		node = &Node{Type: "html", Value: str(string(n.Tokens))}
+	case ast.NodeCodeBlock:
+		node = &Node{Type: "code", Value: str(strings.TrimSuffix(codeBlockCode(n), "\n"))}
+		if info := n.ChildByType(ast.NodeCodeBlockFenceInfoMarker); nil != info && 0 < len(info.CodeBlockInfo) {
+			fields := strings.SplitN(strings.TrimSpace(string(info.CodeBlockInfo)), " ", 2)
+			node.Lang = str(fields[0])
+			if 1 < len(fields) {
+				node.Meta = str(strings.TrimSpace(fields[1]))
+			}
+		}
+		return []*Node{withIAL(node, n)}
+	case ast.NodeMathBlock:
+		node = &Node{Type: "math", Value: str(childTokens(n, ast.NodeMathBlockContent))}
+		return []*Node{withIAL(node, n)}
+	case ast.NodeInlineMath:
+		return []*Node{{Type: "inlineMath", Value: str(childTokens(n, ast.NodeInlineMathContent))}}
+	case ast.NodeYamlFrontMatter:
+		return []*Node{{Type: "yaml", Value: str(childTokens(n, ast.NodeYamlFrontMatterContent))}}
+	case ast.NodeText, ast.NodeLinkText, ast.NodeHTMLEntity:
+		if nil != n.Previous && ast.NodeTaskListItemMarker == n.Previous.Type {
+			// 任务列表项标记符后的空格不属于文本内容
+			return []*Node{{Type: "text", Value: str(strings.TrimLeft(string(n.Tokens), " "))}}
+		}
+		return []*Node{{Type: "text", Value: str(string(n.Tokens))}}
+	case ast.NodeBackslash:
+		return []*Node{{Type: "text", Value: str(childTokens(n, ast.NodeBackslashContent))}}
+	case ast.NodeSoftBreak:
+		return []*Node{{Type: "text", Value: str("\n")}}
+	case ast.NodeHardBreak:
+		return []*Node{{Type: "break"}}
+	case ast.NodeCodeSpan:
+		return []*Node{{Type: "inlineCode", Value: str(childTokens(n, ast.NodeCodeSpanContent))}}
+	case ast.NodeEmphasis:
+		node = &Node{Type: "emphasis"}
+	case ast.NodeStrong:
+		node = &Node{Type: "strong"}
+	case ast.NodeStrikethrough:
+		node = &Node{Type: "delete"}
+	case ast.NodeLink:
+		node = &Node{Type: "link", URL: childTokens(n, ast.NodeLinkDest)}
+		if title := n.ChildByType(ast.NodeLinkTitle); nil != title {
+			node.Title = str(string(title.Tokens))
+		}
+	case ast.NodeImage:
+		node = &Node{Type: "image", URL: childTokens(n, ast.NodeLinkDest), Alt: str(n.Text())}
+		if title := n.ChildByType(ast.NodeLinkTitle); nil != title {
+			node.Title = str(string(title.Tokens))
+		}
+		return []*Node{node}
+	case ast.NodeTable:
+		node = &Node{Type: "table"}
+		for _, align := range n.TableAligns {
+			switch align {
+			case 1:
+				node.Align = append(node.Align, str("left"))
+			case 2:
+				node.Align = append(node.Align, str("center"))
+			case 3:
+				node.Align = append(node.Align, str("right"))
+			default:
+				node.Align = append(node.Align, nil)
+			}
+		}
+	case ast.NodeTableHead:
+		for c := n.FirstChild; nil != c; c = c.Next {
+			ret = append(ret, fromNode(c)...)
+		}
+		return
+	case ast.NodeTableRow:
+		node = &Node{Type: "tableRow"}
+	case ast.NodeTableCell:
+		node = &Node{Type: "tableCell"}
+	case ast.NodeEmoji:
+		if img := n.ChildByType(ast.NodeEmojiImg); nil != img {
+			alias := childTokens(img, ast.NodeEmojiAlias)
+			return []*Node{{Type: "image", URL: imgSrc(img.Tokens), Alt: str(alias), Title: str(alias)}}
+		}
+		return []*Node{{Type: "text", Value: str(childTokens(n, ast.NodeEmojiUnicode))}}
+	case ast.NodeFootnotesDefBlock:
+		for c := n.FirstChild; nil != c; c = c.Next {
+			ret = append(ret, fromNode(c)...)
+		}
+		return
+	case ast.NodeFootnotesDef:
+		label := strings.TrimPrefix(string(n.Tokens), "^")
+		node = &Node{Type: "footnoteDefinition", Identifier: strings.ToLower(label), Label: label}
+	case ast.NodeFootnotesRef:
+		label := strings.TrimPrefix(string(n.Tokens), "^")
+		return []*Node{{Type: "footnoteReference", Identifier: strings.ToLower(label), Label: label}}
+	case ast.NodeLinkRefDefBlock:
+		for c := n.FirstChild; nil != c; c = c.Next {
+			link := c.ChildByType(ast.NodeLink)
+			if nil == link {
+				continue
+			}
+			def := &Node{Type: "definition", Identifier: strings.ToLower(string(c.Tokens)), Label: string(c.Tokens), URL: childTokens(link, ast.NodeLinkDest)}
+			if title := link.ChildByType(ast.NodeLinkTitle); nil != title {
+				def.Title = str(string(title.Tokens))
+			}
+			ret = append(ret, def)
+		}
+		return
+	case ast.NodeToC:
+		return []*Node{{Type: TypeToC}}
+	case ast.NodeBlockRef:
+		node = &Node{Type: TypeBlockRef, ID: childTokens(n, ast.NodeBlockRefID)}
+		if text := n.ChildByType(ast.NodeBlockRefText); nil != text {
+			node.Text = str(text.Text())
+		}
+		return []*Node{node}
+	case ast.NodeBlockEmbed:
+		node = &Node{Type: TypeBlockEmbed, ID: childTokens(n, ast.NodeBlockEmbedID)}
+		if text := n.ChildByType(ast.NodeBlockEmbedText); nil != text && 0 < len(text.Text()) {
+			node.Text = str(text.Text())
+		}
+		return []*Node{withIAL(node, n)}
+	case ast.NodeBlockQueryEmbed:
+		node = &Node{Type: TypeBlockQueryEmbed, Value: str(childTokens(n, ast.NodeBlockQueryEmbedScript))}
+		return []*Node{withIAL(node, n)}
+	case ast.NodeSuperBlock:
+		node = &Node{Type: TypeSuperBlock, Layout: childTokens(n, ast.NodeSuperBlockLayoutMarker)}
+	case ast.NodeTag:
+		node = &Node{Type: TypeTag}
+	case ast.NodeMark:
+		node = &Node{Type: TypeMark}
+	case ast.NodeSup:
+		node = &Node{Type: TypeSuperscript}
+	case ast.NodeSub:
+		node = &Node{Type: TypeSubscript}
+	case ast.NodeGitConflict:
+		buf := bytes.Buffer{}
+		for c := n.FirstChild; nil != c; c = c.Next {
+			buf.Write(c.Tokens)
+			buf.WriteByte('\n')
+		}
+		return []*Node{{Type: TypeGitConflict, Value: str(strings.TrimSuffix(buf.String(), "\n"))}}
+	default:
+		// 标记符、IAL 等节点没有对应的 mdast 节点
+		return nil
+	}
+
+	for c := n.FirstChild; nil != c; c = c.Next {
+		node.Children = appendChildren(node.Children, fromNode(c))
+	}
+	return []*Node{withIAL(node, n)}
+}
+
+// appendChildren 将 nodes 追加到 children 中，相邻的文本节点会被合并。
+func appendChildren(children, nodes []*Node) []*Node {
+	for _, node := range nodes {
+		if last := len(children) - 1; 0 <= last && "text" == node.Type && "text" == children[last].Type {
+			children[last].Value = str(*children[last].Value + *node.Value)
+			continue
+		}
+		children = append(children, node)
+	}
+	return children
+}
+
+// withIAL 将块级节点 n 的 kramdown IAL 保存到 node.Data 中，文档节点的 IAL 保存在根节点上。
+func withIAL(node *Node, n *ast.Node) *Node {
+	if 1 > len(n.KramdownIAL) || !n.IsBlock() {
+		return node
+	}
+
+	ial := map[string]interface{}{}
+	properties := map[string]interface{}{}
+	for _, kv := range n.KramdownIAL {
+		ial[kv[0]] = kv[1]
+		properties[kv[0]] = kv[1]
+	}
+	node.Data = map[string]interface{}{"ial": ial, "hProperties": properties}
+	return node
+}
+
+func codeBlockCode(n *ast.Node) string {
+	return childTokens(n, ast.NodeCodeBlockCode)
+}
+
+func childTokens(n *ast.Node, childType ast.NodeType) string {
+	if c := n.ChildByType(childType); nil != c {
+		return string(c.Tokens)
+	}
+	return ""
+}
+
+// imgSrc 返回 Emoji 图片标签中的 src 属性值。
+func imgSrc(img []byte) string {
+	src := []byte("src=\"")
+	start := bytes.Index(img, src)
+	if 0 > start {
+		return ""
+	}
+	img = img[start+len(src):]
+	if end := bytes.IndexByte(img, '"'); 0 <= end {
+		return string(img[:end])
+	}
+	return ""
+}
@@ -0,0 +1,418 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package mdast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sunlightcs/lute/parse"
+)
+
+// Parse 解析 mdast JSON 数据 data，根节点类型必须是 root。
+func Parse(data []byte) (ret *Node, err error) {
+	ret = &Node{}
+	if err = json.Unmarshal(data, ret); nil != err {
+		return nil, err
+	}
+	if "root" != ret.Type {
+		return nil, errors.New("mdast root node type must be [root], got [" + ret.Type + "]")
+	}
+	return
+}
+
+// ToTree 将 mdast 根节点 root 转换为 Lute 语法树。
+//
+// 转换时先将 mdast 序列化为 Markdown 再进行解析，所以生成的语法树和直接解析 Markdown 的结果完全一致。
+// options 不会被修改，root 中出现的扩展节点（比如 blockRef、superBlock）会在 options 的副本上开启对应的解析选项。
+func ToTree(root *Node, options *parse.Options) *parse.Tree {
+	opts := *options
+	walk(root, func(n *Node) {
+		switch n.Type {
+		case "table":
+			opts.GFMTable = true
+		case "delete":
+			opts.GFMStrikethrough = true
+		case "footnoteDefinition", "footnoteReference":
+			opts.Footnotes = true
+		case "yaml":
+			opts.YamlFrontMatter = true
+		case TypeBlockRef, TypeBlockEmbed, TypeBlockQueryEmbed:
+			opts.BlockRef = true
+		case TypeSuperBlock:
+			opts.SuperBlock = true
+		case TypeTag:
+			opts.Tag = true
+		case TypeMark:
+			opts.Mark = true
+		case TypeSuperscript:
+			opts.Sup = true
+		case TypeSubscript:
+			opts.Sub = true
+		case TypeGitConflict:
+			opts.GitConflict = true
+		case TypeToC:
+			opts.ToC = true
+		}
+		if nil != n.Checked {
+			opts.GFMTaskListItem = true
+		}
+		if _, ok := n.Data["ial"]; ok {
+			opts.KramdownBlockIAL = true
+		}
+	})
+	return parse.Parse("", []byte(ToMarkdown(root)), &opts)
+}
+
+// ToMarkdown 将 mdast 节点 root 序列化为 Markdown 文本。
+func ToMarkdown(root *Node) string {
+	if "root" == root.Type {
+		ret := blocks(root.Children, "\n\n")
+		if docIAL := ial(root); "" != docIAL {
+			ret += "\n" + docIAL
+		}
+		return ret + "\n"
+	}
+	return block(root) + "\n"
+}
+
+func walk(n *Node, visitor func(n *Node)) {
+	visitor(n)
+	for _, c := range n.Children {
+		walk(c, visitor)
+	}
+}
+
+func blocks(nodes []*Node, sep string) string {
+	var ret []string
+	for _, n := range nodes {
+		ret = append(ret, block(n))
+	}
+	return strings.Join(ret, sep)
+}
+
+func block(n *Node) (ret string) {
+	switch n.Type {
+	case "paragraph":
+		ret = inlines(n.Children)
+	case "heading":
+		depth := n.Depth
+		if 1 > depth || 6 < depth {
+			depth = 1
+		}
+		ret = strings.Repeat("#", depth) + " " + inlines(n.Children)
+	case "thematicBreak":
+		ret = "---"
+	case "blockquote":
+		ret = prefixLines(blocks(n.Children, "\n\n"), "> ", ">")
+	case "list":
+		ret = list(n)
+	case "code":
+		fence := "```"
+		for strings.Contains(value(n), fence) {
+			fence += "`"
+		}
+		info := ""
+		if nil != n.Lang {
+			info = *n.Lang
+			if nil != n.Meta && "" != *n.Meta {
+				info += " " + *n.Meta
+			}
+		}
+		ret = fence + info + "\n" + value(n) + "\n" + fence
+	case "html", TypeGitConflict:
+		ret = value(n)
+	case "math":
+		ret = "$$\n" + value(n) + "\n$$"
+	case "yaml":
+		ret = "---\n" + value(n) + "\n---"
+	case "table":
+		ret = table(n)
+	case "definition":
+		ret = "[" + label(n) + "]: " + linkDest(n.URL) + linkTitle(n.Title)
+	case "footnoteDefinition":
+		ret = prefixLines(blocks(n.Children, "\n\n"), "    ", "")
+		ret = "[^" + label(n) + "]: " + strings.TrimPrefix(ret, "    ")
+	case TypeToC:
+		ret = "[toc]"
+	case TypeBlockEmbed:
+		ret = "!" + blockRef(n)
+	case TypeBlockQueryEmbed:
+		ret = "{{" + value(n) + "}}"
+	case TypeSuperBlock:
+		ret = "{{{" + n.Layout + "\n" + blocks(n.Children, "\n\n") + "\n}}}"
+	default:
+		// 块级位置上的行级节点按段落处理
+		ret = inline(n)
+	}
+	return ret + ial(n)
+}
+
+func list(n *Node) string {
+	spread := nil != n.Spread && *n.Spread
+	ordered := nil != n.Ordered && *n.Ordered
+	start := 1
+	if nil != n.Start {
+		start = *n.Start
+	}
+
+	var items []string
+	for i, item := range n.Children {
+		marker := "* "
+		if ordered {
+			marker = strconv.Itoa(start+i) + ". "
+		}
+		itemSpread := spread || (nil != item.Spread && *item.Spread)
+		var content []string
+		for j, c := range item.Children {
+			b := block(c)
+			if 0 < j {
+				sep := "\n"
+				if itemSpread || "paragraph" == c.Type && "paragraph" == item.Children[j-1].Type {
+					sep = "\n\n"
+				}
+				b = sep + b
+			}
+			content = append(content, b)
+		}
+		text := strings.Join(content, "")
+		if nil != item.Checked {
+			if *item.Checked {
+				text = "[x] " + text
+			} else {
+				text = "[ ] " + text
+			}
+		}
+		// 列表项的 IAL 位于列表项内容开头
+		text = strings.TrimPrefix(ial(item), "\n") + text
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(prefixLines(text, indent, ""), indent))
+	}
+	if spread {
+		return strings.Join(items, "\n\n")
+	}
+	return strings.Join(items, "\n")
+}
+
+func table(n *Node) string {
+	buf := &strings.Builder{}
+	for i, row := range n.Children {
+		buf.WriteString("|")
+		for _, cell := range row.Children {
+			buf.WriteString(" " + strings.ReplaceAll(inlines(cell.Children), "\n", " ") + " |")
+		}
+		buf.WriteString("\n")
+		if 0 == i {
+			buf.WriteString("|")
+			for j := range row.Children {
+				delim := " --- |"
+				if j < len(n.Align) && nil != n.Align[j] {
+					switch *n.Align[j] {
+					case "left":
+						delim = " :-- |"
+					case "center":
+						delim = " :-: |"
+					case "right":
+						delim = " --: |"
+					}
+				}
+				buf.WriteString(delim)
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func inlines(nodes []*Node) string {
+	buf := &strings.Builder{}
+	for _, n := range nodes {
+		buf.WriteString(inline(n))
+	}
+	return buf.String()
+}
+
+func inline(n *Node) string {
+	switch n.Type {
+	case "text":
+		return escape(value(n))
+	case "emphasis":
+		return "*" + inlines(n.Children) + "*"
+	case "strong":
+		return "**" + inlines(n.Children) + "**"
+	case "delete":
+		return "~~" + inlines(n.Children) + "~~"
+	case TypeMark:
+		return "==" + inlines(n.Children) + "=="
+	case TypeSuperscript:
+		return "^" + inlines(n.Children) + "^"
+	case TypeSubscript:
+		return "~" + inlines(n.Children) + "~"
+	case TypeTag:
+		return "#" + inlines(n.Children) + "#"
+	case "inlineCode":
+		return codeSpan(value(n))
+	case "break":
+		return "\\\n"
+	case "link":
+		return "[" + inlines(n.Children) + "](" + linkDest(n.URL) + linkTitle(n.Title) + ")"
+	case "image":
+		alt := ""
+		if nil != n.Alt {
+			alt = *n.Alt
+		}
+		return "![" + escape(alt) + "](" + linkDest(n.URL) + linkTitle(n.Title) + ")"
+	case "linkReference":
+		return "[" + inlines(n.Children) + "][" + label(n) + "]"
+	case "imageReference":
+		alt := ""
+		if nil != n.Alt {
+			alt = *n.Alt
+		}
+		return "![" + escape(alt) + "][" + label(n) + "]"
+	case "inlineMath":
+		return "$" + value(n) + "$"
+	case "footnoteReference":
+		return "[^" + label(n) + "]"
+	case "html":
+		return value(n)
+	case TypeBlockRef:
+		return blockRef(n)
+	}
+	if 0 < len(n.Children) {
+		return inlines(n.Children)
+	}
+	return escape(value(n))
+}
+
+func blockRef(n *Node) string {
+	ret := "((" + n.ID
+	if nil != n.Text && "" != *n.Text {
+		ret += " \"" + strings.ReplaceAll(*n.Text, "\"", "&quot;") + "\""
+	}
+	return ret + "))"
+}
+
+// ial 返回节点 data.ial 对应的 kramdown 块级 IAL，id 排在最前面。
+func ial(n *Node) string {
+	attrs, ok := n.Data["ial"].(map[string]interface{})
+	if !ok || 1 > len(attrs) {
+		return ""
+	}
+
+	var names []string
+	for name := range attrs {
+		if "id" != name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := attrs["id"]; ok {
+		names = append([]string{"id"}, names...)
+	}
+	buf := &strings.Builder{}
+	buf.WriteString("\n{:")
+	for _, name := range names {
+		buf.WriteString(" " + name + "=\"" + strings.ReplaceAll(fmt.Sprint(attrs[name]), "\"", "&quot;") + "\"")
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func value(n *Node) string {
+	if nil == n.Value {
+		return ""
+	}
+	return *n.Value
+}
+
+func label(n *Node) string {
+	if "" != n.Label {
+		return n.Label
+	}
+	return n.Identifier
+}
+
+func linkDest(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return url
+}
+
+func linkTitle(title *string) string {
+	if nil == title {
+		return ""
+	}
+	return " \"" + strings.ReplaceAll(*title, "\"", "\\\"") + "\""
+}
+
+func codeSpan(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// escape 转义文本中的 Markdown 标记符，包括位于行首会被识别为列表项的标记符。
+func escape(text string) string {
+	buf := &strings.Builder{}
+	lineStart := true
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\\', '`', '*', '_', '[', ']', '<', '>', '#', '~', '$', '|', '=', '^', '&', '{', '}':
+			buf.WriteByte('\\')
+		case '-', '+':
+			if lineStart && (i+1 == len(text) || ' ' == text[i+1]) {
+				buf.WriteByte('\\')
+			}
+		case '.', ')':
+			if 0 < i && '0' <= text[i-1] && '9' >= text[i-1] && lineDigits(text[:i]) && (i+1 == len(text) || ' ' == text[i+1]) {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteByte(c)
+		lineStart = '\n' == c || (lineStart && ' ' == c)
+	}
+	return buf.String()
+}
+
+// lineDigits 判断 text 的最后一行是否只包含数字（允许前导空格）。
+func lineDigits(text string) bool {
+	line := strings.TrimLeft(text[strings.LastIndexByte(text, '\n')+1:], " ")
+	for i := 0; i < len(line); i++ {
+		if '0' > line[i] || '9' < line[i] {
+			return false
+		}
+	}
+	return "" != line
+}
+
+func prefixLines(text, prefix, blankPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if "" == line {
+			lines[i] = blankPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,86 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// Package mdast 实现了 Lute 语法树和 mdast（https://github.com/syntax-tree/mdast）之间的相互转换，用于和 remark/unified 生态互通。
+//
+// 标准节点按照 mdast 及 GFM、脚注、数学公式、Front Matter 扩展规范转换。Lute 特有的节点使用以下自定义类型：
+//   - blockRef：内容块引用 ((id "text"))，字段 id、text
+//   - blockEmbed：内容块嵌入 !((id "text"))，字段 id、text
+//   - blockQueryEmbed：内容块查询嵌入 {{script}}，字段 value
+//   - superBlock：超级块 {{{layout ... }}}，字段 layout、children
+//   - tag：标签 #tag#，字段 children
+//   - mark：标记 ==mark==，字段 children
+//   - superscript、subscript：上标 ^sup^、下标 ~sub~，字段 children
+//   - gitConflict：Git 冲突标记块，字段 value
+//   - toc：目录 [toc]
+//
+// 块级节点（包括根节点）的 kramdown IAL 保存在 data.ial 中，id 等属性会同时放到 data.hProperties 以便 rehype 输出。
+// Lute 语法树不记录节点的源码位置，所以导出的节点不包含 position 字段。
+package mdast
+
+// 自定义节点类型。
+const (
+	TypeBlockRef        = "blockRef"
+	TypeBlockEmbed      = "blockEmbed"
+	TypeBlockQueryEmbed = "blockQueryEmbed"
+	TypeSuperBlock      = "superBlock"
+	TypeTag             = "tag"
+	TypeMark            = "mark"
+	TypeSuperscript     = "superscript"
+	TypeSubscript       = "subscript"
+	TypeGitConflict     = "gitConflict"
+	TypeToC             = "toc"
+)
+
+// Node 描述了 mdast 节点。
+type Node struct {
+	Type       string                 `json:"type"`
+	Children   []*Node                `json:"children,omitempty"`
+	Value      *string                `json:"value,omitempty"`
+	Depth      int                    `json:"depth,omitempty"`
+	Ordered    *bool                  `json:"ordered,omitempty"`
+	Start      *int                   `json:"start,omitempty"`
+	Spread     *bool                  `json:"spread,omitempty"`
+	Checked    *bool                  `json:"checked,omitempty"`
+	Lang       *string                `json:"lang,omitempty"`
+	Meta       *string                `json:"meta,omitempty"`
+	URL        string                 `json:"url,omitempty"`
+	Title      *string                `json:"title,omitempty"`
+	Alt        *string                `json:"alt,omitempty"`
+	Align      []*string              `json:"align,omitempty"`
+	Identifier string                 `json:"identifier,omitempty"`
+	Label      string                 `json:"label,omitempty"`
+	ID         string                 `json:"id,omitempty"`     // 内容块引用、嵌入的 ID
+	Text       *string                `json:"text,omitempty"`   // 内容块引用、嵌入的锚文本
+	Layout     string                 `json:"layout,omitempty"` // 超级块布局
+	Data       map[string]interface{} `json:"data,omitempty"`
+	Position   *Position              `json:"position,omitempty"`
+}
+
+// Position 描述了 unist 节点位置。
+type Position struct {
+	Start Point `json:"start"`
+	End   Point `json:"end"`
+}
+
+// Point 描述了 unist 位置点。
+type Point struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset,omitempty"`
+}
+
+func str(s string) *string {
+	return &s
+}
+
+func boolean(b bool) *bool {
+	return &b
+}
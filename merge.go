@@ -0,0 +1,32 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/sunlightcs/lute/diff"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+	"github.com/sunlightcs/lute/util"
+)
+
+// Merge 对 Markdown 文本 base、ours 和 theirs 进行三方合并，返回合并后的 Markdown 和冲突列表。
+//
+// 合并以顶层内容块为粒度，开启 kramdown 块级 IAL 时使用内容块 ID 进行匹配，否则按内容相似度匹配。
+// 双方都修改了的内容块会输出为 Git 冲突标记块（<<<<<<< ours、=======、>>>>>>> theirs），合并结果始终是合法的 Markdown。
+func (lute *Lute) Merge(base, ours, theirs string) (merged string, conflicts []*diff.Conflict) {
+	baseTree := parse.Parse("", []byte(base), lute.ParseOptions)
+	ourTree := parse.Parse("", []byte(ours), lute.ParseOptions)
+	theirTree := parse.Parse("", []byte(theirs), lute.ParseOptions)
+	tree, conflicts := diff.Merge(baseTree, ourTree, theirTree)
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	merged = util.BytesToStr(renderer.Render())
+	return
+}
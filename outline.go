@@ -0,0 +1,38 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"encoding/json"
+
+	"github.com/sunlightcs/lute/render"
+)
+
+// RenderOutline 返回 markdown 的大纲 JSON，大纲结构见 parse.Tree.Outline。
+func (lute *Lute) RenderOutline(markdown string) (jsonStr string) {
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		jsonStr = err.Error()
+		return
+	}
+	data, _ := json.Marshal(tree.Outline())
+	return string(data)
+}
+
+// ToC 渲染 markdown 的目录 HTML，可用于在侧栏等位置单独展示目录。目录选项见 render.Options 中 ToC 开头的字段。
+func (lute *Lute) ToC(markdown string) (html string) {
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		html = err.Error()
+		return
+	}
+	return render.ToC(tree, lute.RenderOptions)
+}
@@ -0,0 +1,57 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"encoding/json"
+
+	"github.com/sunlightcs/lute/pandoc"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+	"github.com/sunlightcs/lute/util"
+)
+
+// RenderPandoc 将 markdown 解析后渲染为 Pandoc JSON AST，可以通过 pandoc -f json 转换为 DOCX、ODT、LaTeX 等格式。
+func (lute *Lute) RenderPandoc(markdown string) (json string) {
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		json = err.Error()
+		return
+	}
+	return lute.Tree2Pandoc(tree)
+}
+
+// Tree2Pandoc 将语法树 tree 渲染为 Pandoc JSON AST。
+func (lute *Lute) Tree2Pandoc(tree *parse.Tree) string {
+	data, _ := json.Marshal(pandoc.FromTree(tree))
+	return string(data)
+}
+
+// Pandoc2Tree 将 Pandoc JSON AST（pandoc -t json 的输出）转换为语法树。
+func (lute *Lute) Pandoc2Tree(pandocJSON string) (tree *parse.Tree, err error) {
+	doc, err := pandoc.Parse([]byte(pandocJSON))
+	if nil != err {
+		return
+	}
+	tree = pandoc.ToTree(doc, lute.ParseOptions)
+	return
+}
+
+// Pandoc2Md 将 Pandoc JSON AST 转换为格式化后的 Markdown。
+func (lute *Lute) Pandoc2Md(pandocJSON string) (markdown string, err error) {
+	tree, err := lute.Pandoc2Tree(pandocJSON)
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
@@ -0,0 +1,88 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// Package pandoc 实现了 Lute 语法树和 Pandoc JSON AST（pandoc -f json / -t json）之间的相互转换。
+//
+// 转换通过 mdast 进行：写出时先将语法树转换为 mdast 再转换为 Pandoc 元素，读入时将 Pandoc 元素转换为 mdast 后再构建语法树。
+// Lute 扩展使用带类名的 Div、Span 表示：
+//   - 超级块：Div，类名 super-block，属性 data-layout
+//   - 内容块嵌入：Div，类名 block-embed，属性 data-id
+//   - 内容块查询嵌入：Div，类名 block-query-embed，属性 data-script
+//   - 内容块引用：Span，类名 block-ref，属性 data-id
+//   - 标签、标记：Span，类名 tag、mark
+//
+// 带有 kramdown IAL 的块会包裹在 Div 中，IAL 中的 id 作为 Div 的标识，其余属性作为键值对；标题直接使用 Header 的属性，
+// 文档的 IAL 保存在元数据 lute-ial 中。Pandoc 列表项不支持属性，所以列表项的 IAL 不会被保留。
+// 目录、Git 冲突标记块以及 YAML Front Matter 没有对应的 Pandoc 元素，使用 format 为 markdown 的 RawBlock 保存。
+package pandoc
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// APIVersion 是生成的 Pandoc JSON AST 的 API 版本。
+var APIVersion = []int{1, 23, 1}
+
+// 扩展使用的类名。
+const (
+	ClassSuperBlock      = "super-block"
+	ClassBlockEmbed      = "block-embed"
+	ClassBlockQueryEmbed = "block-query-embed"
+	ClassBlockRef        = "block-ref"
+	ClassTag             = "tag"
+	ClassMark            = "mark"
+)
+
+// MetaIAL 是保存文档 IAL 的元数据名称。
+const MetaIAL = "lute-ial"
+
+// Document 描述了 Pandoc 文档。
+type Document struct {
+	APIVersion []int                  `json:"pandoc-api-version"`
+	Meta       map[string]interface{} `json:"meta"`
+	Blocks     []interface{}          `json:"blocks"`
+}
+
+// Element 描述了 Pandoc 块级或者行级元素，T 为元素类型，C 为元素内容。
+type Element struct {
+	T string      `json:"t"`
+	C interface{} `json:"c,omitempty"`
+}
+
+// Parse 解析 Pandoc JSON 数据 data。
+func Parse(data []byte) (ret *Document, err error) {
+	ret = &Document{}
+	if err = json.Unmarshal(data, ret); nil != err {
+		return nil, err
+	}
+	if 1 > len(ret.APIVersion) {
+		return nil, errors.New("missing pandoc-api-version")
+	}
+	if 1 != ret.APIVersion[0] {
+		return nil, errors.New("unsupported pandoc-api-version")
+	}
+	return
+}
+
+func el(t string, c interface{}) *Element {
+	return &Element{T: t, C: c}
+}
+
+// attr 构造 Pandoc 属性 [id, [classes], [[key, value]]]。
+func attr(id string, classes []string, kvs [][]string) []interface{} {
+	if nil == classes {
+		classes = []string{}
+	}
+	if nil == kvs {
+		kvs = [][]string{}
+	}
+	return []interface{}{id, classes, kvs}
+}
@@ -0,0 +1,404 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package pandoc
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sunlightcs/lute/mdast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+// ToTree 将 Pandoc 文档 doc 转换为 Lute 语法树，options 的处理方式和 mdast.ToTree 一致。
+//
+// 没有对应 Markdown 语法的元素（比如定义列表、行块）会转换为最接近的段落或者列表，非 HTML、Markdown 格式的 RawBlock、RawInline 会被忽略。
+func ToTree(doc *Document, options *parse.Options) *parse.Tree {
+	return mdast.ToTree(ToMdast(doc), options)
+}
+
+// ToMdast 将 Pandoc 文档 doc 转换为 mdast 根节点。
+func ToMdast(doc *Document) *mdast.Node {
+	r := &reader{}
+	root := &mdast.Node{Type: "root", Children: r.blocks(doc.Blocks)}
+	root.Children = append(root.Children, r.notes...)
+	if t, c := element(doc.Meta[MetaIAL]); "MetaMap" == t {
+		ial := map[string]interface{}{}
+		if m, ok := c.(map[string]interface{}); ok {
+			for k, v := range m {
+				if _, value := element(v); nil != value {
+					ial[k] = value
+				}
+			}
+		}
+		root.Data = map[string]interface{}{"ial": ial}
+	}
+	return root
+}
+
+type reader struct {
+	notes []*mdast.Node // Note 元素转换得到的脚注定义
+}
+
+func (r *reader) blocks(elements []interface{}) (ret []*mdast.Node) {
+	for _, e := range elements {
+		ret = append(ret, r.block(e)...)
+	}
+	return
+}
+
+func (r *reader) block(e interface{}) []*mdast.Node {
+	t, c := element(e)
+	switch t {
+	case "Plain", "Para":
+		inlines := list(c)
+		if 1 == len(inlines) {
+			if mt, mc := element(inlines[0]); "Math" == mt {
+				if kind, _ := element(at(mc, 0)); "DisplayMath" == kind {
+					return []*mdast.Node{{Type: "math", Value: str(stringAt(mc, 1))}}
+				}
+			}
+		}
+		return []*mdast.Node{{Type: "paragraph", Children: r.inlines(inlines)}}
+	case "Header":
+		heading := &mdast.Node{Type: "heading", Depth: intAt(c, 0), Children: r.inlines(list(at(c, 2)))}
+		setIAL(heading, at(c, 1))
+		return []*mdast.Node{heading}
+	case "HorizontalRule":
+		return []*mdast.Node{{Type: "thematicBreak"}}
+	case "BlockQuote":
+		return []*mdast.Node{{Type: "blockquote", Children: r.blocks(list(c))}}
+	case "BulletList":
+		return []*mdast.Node{r.list(list(c), false, 1)}
+	case "OrderedList":
+		return []*mdast.Node{r.list(list(at(c, 1)), true, intAt(at(c, 0), 0))}
+	case "CodeBlock":
+		code := &mdast.Node{Type: "code", Value: str(stringAt(c, 1))}
+		_, classes, kvs := attrs(at(c, 0))
+		if 0 < len(classes) {
+			code.Lang = str(classes[0])
+		}
+		if meta, ok := kvs["meta"]; ok {
+			code.Meta = str(meta)
+		}
+		return []*mdast.Node{code}
+	case "RawBlock":
+		if format := stringAt(c, 0); "html" == format || "markdown" == format {
+			return []*mdast.Node{{Type: "html", Value: str(stringAt(c, 1))}}
+		}
+	case "Table":
+		return []*mdast.Node{r.table(list(c))}
+	case "Div":
+		return r.div(at(c, 0), list(at(c, 1)))
+	case "LineBlock":
+		var inlines []*mdast.Node
+		for i, line := range list(c) {
+			if 0 < i {
+				inlines = append(inlines, &mdast.Node{Type: "break"})
+			}
+			inlines = append(inlines, r.inlines(list(line))...)
+		}
+		return []*mdast.Node{{Type: "paragraph", Children: inlines}}
+	case "DefinitionList":
+		var ret []*mdast.Node
+		for _, item := range list(c) {
+			ret = append(ret, &mdast.Node{Type: "paragraph", Children: []*mdast.Node{{Type: "strong", Children: r.inlines(list(at(item, 0)))}}})
+			for _, def := range list(at(item, 1)) {
+				ret = append(ret, r.blocks(list(def))...)
+			}
+		}
+		return ret
+	case "Figure":
+		return r.blocks(list(at(c, 2)))
+	}
+	return nil
+}
+
+func (r *reader) list(items []interface{}, ordered bool, start int) *mdast.Node {
+	tight := true
+	ret := &mdast.Node{Type: "list", Ordered: &ordered}
+	if ordered {
+		ret.Start = &start
+	}
+	for _, item := range items {
+		blocks := list(item)
+		listItem := &mdast.Node{Type: "listItem"}
+		for i, b := range blocks {
+			t, c := element(b)
+			if "Para" == t {
+				tight = false
+			}
+			if 0 == i && ("Plain" == t || "Para" == t) {
+				// 识别 Pandoc 任务列表项 ☒、☐
+				inlines := list(c)
+				if 2 <= len(inlines) {
+					if st, sc := element(inlines[0]); "Str" == st {
+						if box, _ := sc.(string); "☒" == box || "☐" == box {
+							checked := "☒" == box
+							listItem.Checked = &checked
+							b = &Element{T: t, C: inlines[2:]}
+						}
+					}
+				}
+			}
+			listItem.Children = append(listItem.Children, r.block(b)...)
+		}
+		ret.Children = append(ret.Children, listItem)
+	}
+	spread := !tight
+	ret.Spread = &spread
+	for _, item := range ret.Children {
+		item.Spread = &spread
+	}
+	return ret
+}
+
+func (r *reader) table(c []interface{}) *mdast.Node {
+	ret := &mdast.Node{Type: "table"}
+	for _, spec := range list(at(c, 2)) {
+		var align *string
+		switch t, _ := element(at(spec, 0)); t {
+		case "AlignLeft":
+			align = str("left")
+		case "AlignCenter":
+			align = str("center")
+		case "AlignRight":
+			align = str("right")
+		}
+		ret.Align = append(ret.Align, align)
+	}
+
+	rows := list(at(at(c, 3), 1))
+	for _, body := range list(at(c, 4)) {
+		rows = append(rows, list(at(body, 2))...)
+		rows = append(rows, list(at(body, 3))...)
+	}
+	rows = append(rows, list(at(at(c, 5), 1))...)
+	for _, row := range rows {
+		tableRow := &mdast.Node{Type: "tableRow"}
+		for _, cell := range list(at(row, 1)) {
+			tableCell := &mdast.Node{Type: "tableCell"}
+			for i, b := range list(at(cell, 4)) {
+				if 0 < i {
+					tableCell.Children = append(tableCell.Children, &mdast.Node{Type: "break"})
+				}
+				_, bc := element(b)
+				tableCell.Children = append(tableCell.Children, r.inlines(list(bc))...)
+			}
+			tableRow.Children = append(tableRow.Children, tableCell)
+		}
+		ret.Children = append(ret.Children, tableRow)
+	}
+	return ret
+}
+
+func (r *reader) div(attr interface{}, blocks []interface{}) []*mdast.Node {
+	id, classes, kvs := attrs(attr)
+	for _, class := range classes {
+		switch class {
+		case ClassSuperBlock:
+			return []*mdast.Node{{Type: mdast.TypeSuperBlock, Layout: kvs["data-layout"], Children: r.blocks(blocks)}}
+		case ClassBlockEmbed:
+			embed := &mdast.Node{Type: mdast.TypeBlockEmbed, ID: kvs["data-id"]}
+			if text := plainText(r.blocks(blocks)); "" != text {
+				embed.Text = str(text)
+			}
+			return []*mdast.Node{embed}
+		case ClassBlockQueryEmbed:
+			return []*mdast.Node{{Type: mdast.TypeBlockQueryEmbed, Value: str(kvs["data-script"])}}
+		}
+	}
+
+	ret := r.blocks(blocks)
+	if 1 == len(ret) && 1 > len(classes) && ("" != id || 0 < len(kvs)) {
+		// 只包含一个块并且带有属性的 Div 是 IAL 的载体
+		setIAL(ret[0], attr)
+	}
+	return ret
+}
+
+func (r *reader) inlines(elements []interface{}) (ret []*mdast.Node) {
+	for _, e := range elements {
+		ret = append(ret, r.inline(e)...)
+	}
+	return
+}
+
+func (r *reader) inline(e interface{}) []*mdast.Node {
+	t, c := element(e)
+	switch t {
+	case "Str":
+		s, _ := c.(string)
+		return []*mdast.Node{{Type: "text", Value: str(s)}}
+	case "Space":
+		return []*mdast.Node{{Type: "text", Value: str(" ")}}
+	case "SoftBreak":
+		return []*mdast.Node{{Type: "text", Value: str("\n")}}
+	case "LineBreak":
+		return []*mdast.Node{{Type: "break"}}
+	case "Emph":
+		return []*mdast.Node{{Type: "emphasis", Children: r.inlines(list(c))}}
+	case "Strong":
+		return []*mdast.Node{{Type: "strong", Children: r.inlines(list(c))}}
+	case "Strikeout":
+		return []*mdast.Node{{Type: "delete", Children: r.inlines(list(c))}}
+	case "Superscript":
+		return []*mdast.Node{{Type: mdast.TypeSuperscript, Children: r.inlines(list(c))}}
+	case "Subscript":
+		return []*mdast.Node{{Type: mdast.TypeSubscript, Children: r.inlines(list(c))}}
+	case "Underline", "SmallCaps":
+		return r.inlines(list(c))
+	case "Quoted":
+		quote := "\""
+		if kind, _ := element(at(c, 0)); "SingleQuote" == kind {
+			quote = "'"
+		}
+		ret := []*mdast.Node{{Type: "text", Value: str(quote)}}
+		ret = append(ret, r.inlines(list(at(c, 1)))...)
+		return append(ret, &mdast.Node{Type: "text", Value: str(quote)})
+	case "Cite":
+		return r.inlines(list(at(c, 1)))
+	case "Code":
+		return []*mdast.Node{{Type: "inlineCode", Value: str(stringAt(c, 1))}}
+	case "Math":
+		return []*mdast.Node{{Type: "inlineMath", Value: str(stringAt(c, 1))}}
+	case "RawInline":
+		if format := stringAt(c, 0); "html" == format || "markdown" == format {
+			return []*mdast.Node{{Type: "html", Value: str(stringAt(c, 1))}}
+		}
+	case "Link":
+		target := list(at(c, 2))
+		link := &mdast.Node{Type: "link", URL: stringAt(target, 0), Children: r.inlines(list(at(c, 1)))}
+		if title := stringAt(target, 1); "" != title {
+			link.Title = str(title)
+		}
+		return []*mdast.Node{link}
+	case "Image":
+		target := list(at(c, 2))
+		image := &mdast.Node{Type: "image", URL: stringAt(target, 0), Alt: str(plainText(r.inlines(list(at(c, 1)))))}
+		if title := stringAt(target, 1); "" != title {
+			image.Title = str(title)
+		}
+		return []*mdast.Node{image}
+	case "Note":
+		label := strconv.Itoa(len(r.notes) + 1)
+		r.notes = append(r.notes, &mdast.Node{Type: "footnoteDefinition", Identifier: label, Label: label})
+		def := r.notes[len(r.notes)-1]
+		def.Children = r.blocks(list(c))
+		return []*mdast.Node{{Type: "footnoteReference", Identifier: label, Label: label}}
+	case "Span":
+		_, classes, kvs := attrs(at(c, 0))
+		children := r.inlines(list(at(c, 1)))
+		for _, class := range classes {
+			switch class {
+			case ClassBlockRef:
+				return []*mdast.Node{{Type: mdast.TypeBlockRef, ID: kvs["data-id"], Text: str(plainText(children))}}
+			case ClassTag:
+				return []*mdast.Node{{Type: mdast.TypeTag, Children: children}}
+			case ClassMark:
+				return []*mdast.Node{{Type: mdast.TypeMark, Children: children}}
+			}
+		}
+		return children
+	}
+	return nil
+}
+
+// setIAL 将 Pandoc 属性 attr 中的标识和键值对保存为 n 的 IAL。
+func setIAL(n *mdast.Node, attr interface{}) {
+	id, _, kvs := attrs(attr)
+	if "" == id && 1 > len(kvs) {
+		return
+	}
+
+	ial := map[string]interface{}{}
+	if "" != id {
+		ial["id"] = id
+	}
+	for k, v := range kvs {
+		ial[k] = v
+	}
+	n.Data = map[string]interface{}{"ial": ial}
+}
+
+func plainText(nodes []*mdast.Node) string {
+	buf := &strings.Builder{}
+	var walk func(nodes []*mdast.Node)
+	walk = func(nodes []*mdast.Node) {
+		for _, n := range nodes {
+			if nil != n.Value && ("text" == n.Type || "inlineCode" == n.Type) {
+				buf.WriteString(*n.Value)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(nodes)
+	return buf.String()
+}
+
+// element 返回 Pandoc 元素 e 的类型和内容。
+func element(e interface{}) (t string, c interface{}) {
+	switch v := e.(type) {
+	case map[string]interface{}:
+		t, _ = v["t"].(string)
+		c = v["c"]
+	case *Element:
+		t, c = v.T, v.C
+	}
+	return
+}
+
+// attrs 解析 Pandoc 属性 [id, [classes], [[key, value]]]。
+func attrs(attr interface{}) (id string, classes []string, kvs map[string]string) {
+	id = stringAt(attr, 0)
+	for _, class := range list(at(attr, 1)) {
+		if s, ok := class.(string); ok {
+			classes = append(classes, s)
+		}
+	}
+	kvs = map[string]string{}
+	for _, kv := range list(at(attr, 2)) {
+		kvs[stringAt(kv, 0)] = stringAt(kv, 1)
+	}
+	return
+}
+
+func list(v interface{}) []interface{} {
+	ret, _ := v.([]interface{})
+	return ret
+}
+
+func at(v interface{}, i int) interface{} {
+	l := list(v)
+	if i < len(l) {
+		return l[i]
+	}
+	return nil
+}
+
+func stringAt(v interface{}, i int) string {
+	ret, _ := at(v, i).(string)
+	return ret
+}
+
+func intAt(v interface{}, i int) int {
+	switch n := at(v, i).(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+func str(s string) *string {
+	return &s
+}
@@ -0,0 +1,336 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package pandoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sunlightcs/lute/mdast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+// FromTree 将 Lute 语法树 tree 转换为 Pandoc 文档。
+func FromTree(tree *parse.Tree) *Document {
+	root := mdast.FromTree(tree)
+	w := &writer{notes: map[string]*mdast.Node{}}
+	for _, n := range root.Children {
+		if "footnoteDefinition" == n.Type {
+			w.notes[n.Identifier] = n
+		}
+	}
+	ret := &Document{APIVersion: APIVersion, Meta: map[string]interface{}{}, Blocks: w.blocks(root.Children)}
+	if id, kvs := ial(root); "" != id || 0 < len(kvs) {
+		// 文档 IAL 保存在元数据中
+		ial := map[string]interface{}{}
+		if "" != id {
+			ial["id"] = el("MetaString", id)
+		}
+		for _, kv := range kvs {
+			ial[kv[0]] = el("MetaString", kv[1])
+		}
+		ret.Meta[MetaIAL] = el("MetaMap", ial)
+	}
+	return ret
+}
+
+type writer struct {
+	notes map[string]*mdast.Node // 脚注定义，脚注在引用处以 Note 元素输出
+}
+
+func (w *writer) blocks(nodes []*mdast.Node) []interface{} {
+	ret := []interface{}{}
+	for _, n := range nodes {
+		if b := w.block(n, false); nil != b {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+// block 将 mdast 块级节点 n 转换为 Pandoc 块级元素，plain 为真时段落输出为 Plain（紧凑列表项）。
+func (w *writer) block(n *mdast.Node, plain bool) (ret *Element) {
+	id, kvs := ial(n)
+	switch n.Type {
+	case "paragraph":
+		if plain {
+			ret = el("Plain", w.inlines(n.Children))
+		} else {
+			ret = el("Para", w.inlines(n.Children))
+		}
+	case "heading":
+		// 标题直接使用 Header 的属性保存 IAL
+		return el("Header", []interface{}{n.Depth, attr(id, nil, kvs), w.inlines(n.Children)})
+	case "thematicBreak":
+		ret = &Element{T: "HorizontalRule"}
+	case "blockquote":
+		ret = el("BlockQuote", w.blocks(n.Children))
+	case "list":
+		ret = w.list(n)
+	case "code":
+		var classes []string
+		var codeKVs [][]string
+		if nil != n.Lang {
+			classes = append(classes, *n.Lang)
+		}
+		if nil != n.Meta {
+			codeKVs = append(codeKVs, []string{"meta", *n.Meta})
+		}
+		ret = el("CodeBlock", []interface{}{attr("", classes, codeKVs), value(n)})
+	case "html":
+		ret = el("RawBlock", []interface{}{"html", value(n)})
+	case "math":
+		ret = el("Para", []interface{}{el("Math", []interface{}{&Element{T: "DisplayMath"}, value(n)})})
+	case "yaml":
+		ret = el("RawBlock", []interface{}{"markdown", "---\n" + value(n) + "\n---"})
+	case mdast.TypeToC:
+		ret = el("RawBlock", []interface{}{"markdown", "[toc]"})
+	case mdast.TypeGitConflict:
+		ret = el("RawBlock", []interface{}{"markdown", value(n)})
+	case "table":
+		ret = w.table(n)
+	case "footnoteDefinition", "definition":
+		// 脚注在引用处输出，链接引用定义已经在解析时应用到链接上
+		return nil
+	case mdast.TypeSuperBlock:
+		ret = el("Div", []interface{}{attr("", []string{ClassSuperBlock}, [][]string{{"data-layout", n.Layout}}), w.blocks(n.Children)})
+	case mdast.TypeBlockEmbed:
+		var content []interface{}
+		if nil != n.Text {
+			content = append(content, el("Plain", w.text(*n.Text)))
+		}
+		ret = el("Div", []interface{}{attr("", []string{ClassBlockEmbed}, [][]string{{"data-id", n.ID}}), nonNil(content)})
+	case mdast.TypeBlockQueryEmbed:
+		ret = el("Div", []interface{}{attr("", []string{ClassBlockQueryEmbed}, [][]string{{"data-script", value(n)}}), []interface{}{}})
+	default:
+		ret = el("Para", w.inlines([]*mdast.Node{n}))
+	}
+
+	if "" != id || 0 < len(kvs) {
+		ret = el("Div", []interface{}{attr(id, nil, kvs), []interface{}{ret}})
+	}
+	return
+}
+
+func (w *writer) list(n *mdast.Node) *Element {
+	tight := nil == n.Spread || !*n.Spread
+	items := []interface{}{}
+	for _, item := range n.Children {
+		var blocks []interface{}
+		for i, c := range item.Children {
+			b := w.block(c, tight)
+			if nil == b {
+				continue
+			}
+			if 0 == i && nil != item.Checked {
+				// Pandoc 使用 ☒、☐ 表示任务列表项
+				box := "☐"
+				if *item.Checked {
+					box = "☒"
+				}
+				if "Plain" == b.T || "Para" == b.T {
+					b.C = append([]interface{}{el("Str", box), &Element{T: "Space"}}, b.C.([]interface{})...)
+				}
+			}
+			blocks = append(blocks, b)
+		}
+		items = append(items, nonNil(blocks))
+	}
+
+	if nil != n.Ordered && *n.Ordered {
+		start := 1
+		if nil != n.Start {
+			start = *n.Start
+		}
+		return el("OrderedList", []interface{}{[]interface{}{start, &Element{T: "Decimal"}, &Element{T: "Period"}}, items})
+	}
+	return el("BulletList", items)
+}
+
+func (w *writer) table(n *mdast.Node) *Element {
+	var colSpecs []interface{}
+	cols := 0
+	if 0 < len(n.Children) {
+		cols = len(n.Children[0].Children)
+	}
+	for i := 0; i < cols; i++ {
+		align := &Element{T: "AlignDefault"}
+		if i < len(n.Align) && nil != n.Align[i] {
+			switch *n.Align[i] {
+			case "left":
+				align = &Element{T: "AlignLeft"}
+			case "center":
+				align = &Element{T: "AlignCenter"}
+			case "right":
+				align = &Element{T: "AlignRight"}
+			}
+		}
+		colSpecs = append(colSpecs, []interface{}{align, &Element{T: "ColWidthDefault"}})
+	}
+
+	row := func(r *mdast.Node) []interface{} {
+		cells := []interface{}{}
+		for _, cell := range r.Children {
+			// 单元格使用列定义中的对齐方式
+			cells = append(cells, []interface{}{attr("", nil, nil), &Element{T: "AlignDefault"}, 1, 1, []interface{}{el("Plain", w.inlines(cell.Children))}})
+		}
+		return []interface{}{attr("", nil, nil), cells}
+	}
+	head, body := []interface{}{}, []interface{}{}
+	for i, r := range n.Children {
+		if 0 == i {
+			head = append(head, row(r))
+		} else {
+			body = append(body, row(r))
+		}
+	}
+	return el("Table", []interface{}{
+		attr("", nil, nil),
+		[]interface{}{nil, []interface{}{}},
+		nonNil(colSpecs),
+		[]interface{}{attr("", nil, nil), head},
+		[]interface{}{[]interface{}{attr("", nil, nil), 0, []interface{}{}, body}},
+		[]interface{}{attr("", nil, nil), []interface{}{}},
+	})
+}
+
+func (w *writer) inlines(nodes []*mdast.Node) []interface{} {
+	ret := []interface{}{}
+	for _, n := range nodes {
+		ret = append(ret, w.inline(n)...)
+	}
+	return ret
+}
+
+func (w *writer) inline(n *mdast.Node) []interface{} {
+	switch n.Type {
+	case "text":
+		return w.text(value(n))
+	case "emphasis":
+		return []interface{}{el("Emph", w.inlines(n.Children))}
+	case "strong":
+		return []interface{}{el("Strong", w.inlines(n.Children))}
+	case "delete":
+		return []interface{}{el("Strikeout", w.inlines(n.Children))}
+	case mdast.TypeSuperscript:
+		return []interface{}{el("Superscript", w.inlines(n.Children))}
+	case mdast.TypeSubscript:
+		return []interface{}{el("Subscript", w.inlines(n.Children))}
+	case mdast.TypeMark:
+		return []interface{}{el("Span", []interface{}{attr("", []string{ClassMark}, nil), w.inlines(n.Children)})}
+	case mdast.TypeTag:
+		return []interface{}{el("Span", []interface{}{attr("", []string{ClassTag}, nil), w.inlines(n.Children)})}
+	case mdast.TypeBlockRef:
+		text := n.ID
+		if nil != n.Text && "" != *n.Text {
+			text = *n.Text
+		}
+		return []interface{}{el("Span", []interface{}{attr("", []string{ClassBlockRef}, [][]string{{"data-id", n.ID}}), w.text(text)})}
+	case "inlineCode":
+		return []interface{}{el("Code", []interface{}{attr("", nil, nil), value(n)})}
+	case "break":
+		return []interface{}{&Element{T: "LineBreak"}}
+	case "link":
+		return []interface{}{el("Link", []interface{}{attr("", nil, nil), w.inlines(n.Children), []interface{}{n.URL, title(n)}})}
+	case "image":
+		alt := ""
+		if nil != n.Alt {
+			alt = *n.Alt
+		}
+		return []interface{}{el("Image", []interface{}{attr("", nil, nil), w.text(alt), []interface{}{n.URL, title(n)}})}
+	case "inlineMath":
+		return []interface{}{el("Math", []interface{}{&Element{T: "InlineMath"}, value(n)})}
+	case "footnoteReference":
+		var blocks []interface{}
+		if def := w.notes[n.Identifier]; nil != def {
+			blocks = w.blocks(def.Children)
+		}
+		return []interface{}{el("Note", nonNil(blocks))}
+	case "html":
+		return []interface{}{el("RawInline", []interface{}{"html", value(n)})}
+	}
+	return w.inlines(n.Children)
+}
+
+// text 将文本按照空白拆分为 Str、Space 和 SoftBreak 元素。
+func (w *writer) text(text string) (ret []interface{}) {
+	ret = []interface{}{}
+	var word strings.Builder
+	flush := func() {
+		if 0 < word.Len() {
+			ret = append(ret, el("Str", word.String()))
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch r {
+		case ' ', '\t':
+			flush()
+			if last := len(ret) - 1; 0 > last || "Space" != ret[last].(*Element).T {
+				ret = append(ret, &Element{T: "Space"})
+			}
+		case '\n':
+			flush()
+			if last := len(ret) - 1; 0 <= last && "Space" == ret[last].(*Element).T {
+				ret = ret[:last]
+			}
+			ret = append(ret, &Element{T: "SoftBreak"})
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return
+}
+
+// ial 返回 mdast 节点保存的 IAL 中的 id 和其余属性。
+func ial(n *mdast.Node) (id string, kvs [][]string) {
+	attrs, ok := n.Data["ial"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	var names []string
+	for name := range attrs {
+		if "id" == name {
+			id = fmt.Sprint(attrs[name])
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		kvs = append(kvs, []string{name, fmt.Sprint(attrs[name])})
+	}
+	return
+}
+
+func value(n *mdast.Node) string {
+	if nil == n.Value {
+		return ""
+	}
+	return *n.Value
+}
+
+func title(n *mdast.Node) string {
+	if nil == n.Title {
+		return ""
+	}
+	return *n.Title
+}
+
+func nonNil(elements []interface{}) []interface{} {
+	if nil == elements {
+		return []interface{}{}
+	}
+	return elements
+}
@@ -36,6 +36,7 @@ func (t *Tree) parseBlocks() {
 			}
 		}
 
+		t.Context.lineNum++
 		t.incorporateLine(line)
 		lines++
 	}
@@ -12,6 +12,12 @@ package parse
 
 import (
 	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
 	"github.com/sunlightcs/lute/ast"
 	"github.com/sunlightcs/lute/util"
 )
@@ -57,3 +63,255 @@ func (t *Tree) parseHeadingID(block *ast.Node, ctx *InlineContext) (ret *ast.Nod
 	}
 	return &ast.Node{Type: ast.NodeHeadingID, Tokens: id}
 }
+
+// 标题 ID 生成方式。
+const (
+	HeadingIDSlugDefault = ""       // 默认方式，字母和数字以外的字符替换为 -，重复的 ID 追加 - 区分
+	HeadingIDSlugGitHub  = "github" // 兼容 GitHub 的锚点
+	HeadingIDSlugGitLab  = "gitlab" // 兼容 GitLab 的锚点
+	HeadingIDSlugPinyin  = "pinyin" // 汉字转写为拼音，其他规则同 GitHub
+)
+
+// HeadingID 返回标题 heading 的 ID，ID 按照解析选项 HeadingIDSlugFunc 或者 HeadingIDSlug 设置的方式生成。
+//
+// 使用 {#id} 显式指定的 ID 优先，其他方式生成的 ID 会避开显式指定的 ID。除默认方式外，同一文档中重复的 ID 按照
+// 文档顺序追加 -1、-2 区分，生成的 ID 为空时（比如标题只有 Emoji）使用 heading。
+//
+// 除默认方式外生成的 ID 按照生成方式缓存在语法树上，不会写入节点，所以切换生成方式或者从 JSON 加载语法树后不会用到过期的 ID。
+func (t *Tree) HeadingID(heading *ast.Node) string {
+	if nil == t.Context || nil == t.Context.ParseOption {
+		return HeadingID(heading)
+	}
+
+	slug := headingSlugFunc(t.Context.ParseOption)
+	if nil == slug {
+		return HeadingID(heading)
+	}
+
+	key := t.Context.ParseOption.HeadingIDSlug
+	if nil != t.Context.ParseOption.HeadingIDSlugFunc {
+		key = "func:" + strconv.FormatUint(uint64(reflect.ValueOf(t.Context.ParseOption.HeadingIDSlugFunc).Pointer()), 16)
+	}
+	id, ok := t.headingIDs[heading]
+	if !ok || key != t.headingIDsKey {
+		t.headingIDs, t.headingIDsKey = headingSlugIDs(heading, slug), key
+		id = t.headingIDs[heading]
+	}
+	return id
+}
+
+// HeadingID 使用默认方式返回标题 heading 的 ID。ID 由标题文本或者 {#id} 规范化生成，同一文档中重复的 ID 会追加 - 区分。
+func HeadingID(heading *ast.Node) (ret string) {
+	if 0 == len(util.StrToBytes(heading.HeadingNormalizedID)) {
+		headingID0(heading)
+	}
+	return heading.HeadingNormalizedID
+}
+
+func headingID0(heading *ast.Node) {
+	var root *ast.Node
+	for root = heading.Parent; ast.NodeDocument != root.Type; root = root.Parent {
+	}
+
+	idOccurs := map[string]int{}
+	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			if ast.NodeHeading == n.Type {
+				id := normalizeHeadingID(n)
+				for ; 0 < idOccurs[id]; id += "-" {
+				}
+				n.HeadingNormalizedID = id
+				idOccurs[id] = 1
+			}
+		}
+		return ast.WalkContinue
+	})
+}
+
+func normalizeHeadingID(heading *ast.Node) (ret string) {
+	headingID := heading.ChildByType(ast.NodeHeadingID)
+	var id string
+	if nil != headingID {
+		id = util.BytesToStr(headingID.Tokens)
+	}
+	if "" == id {
+		id = heading.Text()
+	}
+
+	id = strings.TrimLeft(id, "#")
+	id = strings.ReplaceAll(id, util.Caret, "")
+	for _, r := range id {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			ret += string(r)
+		} else {
+			ret += "-"
+		}
+	}
+	return
+}
+
+// headingSlugFunc 返回解析选项 options 设置的标题 ID 生成函数，使用默认方式时返回 nil。
+func headingSlugFunc(options *Options) func(text string) string {
+	if nil != options.HeadingIDSlugFunc {
+		return options.HeadingIDSlugFunc
+	}
+
+	switch options.HeadingIDSlug {
+	case HeadingIDSlugGitHub:
+		return GitHubSlug
+	case HeadingIDSlugGitLab:
+		return GitLabSlug
+	case HeadingIDSlugPinyin:
+		return PinyinSlug
+	}
+	return nil
+}
+
+// headingSlugIDs 使用 slug 为标题 heading 所在文档中的所有标题生成 ID。
+func headingSlugIDs(heading *ast.Node, slug func(text string) string) (ret map[*ast.Node]string) {
+	var root *ast.Node
+	for root = heading; nil != root.Parent; root = root.Parent {
+	}
+
+	var headings []*ast.Node
+	explicitIDs := map[string]bool{}
+	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && ast.NodeHeading == n.Type {
+			headings = append(headings, n)
+			if id := explicitHeadingID(n); "" != id {
+				explicitIDs[id] = true
+			}
+		}
+		return ast.WalkContinue
+	})
+
+	ret = map[*ast.Node]string{}
+	used := map[string]bool{}
+	for _, h := range headings {
+		id := explicitHeadingID(h)
+		if "" != id && !used[id] {
+			used[id] = true
+			ret[h] = id
+			continue
+		}
+
+		if "" == id {
+			if id = slug(headingSlugText(h)); "" == id {
+				id = "heading"
+			}
+		}
+		base := id
+		for i := 1; used[id] || explicitIDs[id]; i++ {
+			id = base + "-" + strconv.Itoa(i)
+		}
+		used[id] = true
+		ret[h] = id
+	}
+	return
+}
+
+// explicitHeadingID 返回标题 heading 使用 {#id} 显式指定的 ID，没有指定时返回空字符串。
+func explicitHeadingID(heading *ast.Node) string {
+	headingID := heading.ChildByType(ast.NodeHeadingID)
+	if nil == headingID {
+		return ""
+	}
+	ret := strings.TrimLeft(util.BytesToStr(headingID.Tokens), "#")
+	return strings.ReplaceAll(ret, util.Caret, "")
+}
+
+// headingSlugText 返回用于生成标题 heading ID 的文本，和 GitHub 一样包含代码的内容。
+func headingSlugText(heading *ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.Walk(heading, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		switch n.Type {
+		case ast.NodeText, ast.NodeLinkText, ast.NodeCodeSpanContent, ast.NodeBackslashContent, ast.NodeBlockRefText, ast.NodeBlockEmbedText:
+			if ast.NodeLinkText == n.Type && ast.NodeImage == n.Parent.Type {
+				return ast.WalkContinue
+			}
+			buf.Write(n.Tokens)
+		}
+		return ast.WalkContinue
+	})
+	return strings.ReplaceAll(buf.String(), util.Caret, "")
+}
+
+// GitHubSlug 按照 GitHub 的规则将标题文本 text 转换为 ID：转为小写，去掉字母、数字、_ 和 - 以外的字符，空格替换为 -。
+func GitHubSlug(text string) string {
+	buf := &strings.Builder{}
+	for _, r := range strings.ToLower(text) {
+		if isSlugWordRune(r) || '-' == r {
+			buf.WriteRune(r)
+		} else if ' ' == r {
+			buf.WriteByte('-')
+		}
+	}
+	return buf.String()
+}
+
+// GitLabSlug 按照 GitLab 的规则将标题文本 text 转换为 ID：在 GitHub 规则的基础上将连续的 - 合并为一个，纯数字的 ID
+// 加上 anchor- 前缀。
+func GitLabSlug(text string) string {
+	ret := squeezeHyphens(GitHubSlug(text))
+	if "" != ret && "" == strings.TrimFunc(ret, unicode.IsDigit) {
+		ret = "anchor-" + ret
+	}
+	return ret
+}
+
+// PinyinSlug 将标题文本 text 中的汉字转写为不带声调的拼音后按照 GitHub 的规则转换为 ID，拼音之间使用 - 分隔，
+// 比如“中文 标题”转换为 zhong-wen-biao-ti。
+func PinyinSlug(text string) string {
+	buf := &strings.Builder{}
+	afterPinyin := false
+	for _, r := range text {
+		if py := Pinyin(r); "" != py {
+			if 0 < buf.Len() {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(py)
+			afterPinyin = true
+			continue
+		}
+
+		if afterPinyin && isSlugWordRune(r) {
+			buf.WriteByte(' ')
+		}
+		afterPinyin = false
+		buf.WriteRune(r)
+	}
+	return strings.Trim(squeezeHyphens(GitHubSlug(buf.String())), "-")
+}
+
+func isSlugWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.M, r) || unicode.Is(unicode.Pc, r)
+}
+
+func squeezeHyphens(id string) string {
+	for strings.Contains(id, "--") {
+		id = strings.ReplaceAll(id, "--", "-")
+	}
+	return id
+}
+
+var (
+	pinyinMap  map[rune]string
+	pinyinOnce sync.Once
+)
+
+// Pinyin 返回汉字 r 不带声调的拼音（ü 写作 v），多音字只返回一个常用读音，r 不是汉字时返回空字符串。
+func Pinyin(r rune) string {
+	pinyinOnce.Do(func() {
+		pinyinMap = make(map[rune]string, 21000)
+		for _, item := range pinyinTable {
+			i := strings.IndexByte(item, ' ')
+			for _, han := range item[i+1:] {
+				pinyinMap[han] = item[:i]
+			}
+		}
+	})
+	return pinyinMap[r]
+}
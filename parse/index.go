@@ -0,0 +1,48 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/util"
+)
+
+// Index 返回树的节点 ID 索引，首次调用时建立索引。通过节点修改函数调整树结构时索引会同步更新。
+func (t *Tree) Index() *ast.Index {
+	return ast.BuildIndex(t.Root)
+}
+
+// Backlinks 返回树中所有指向 id 的内容块引用节点和内容块嵌入节点，按文档顺序排列。
+func (t *Tree) Backlinks(id string) (ret []*ast.Node) {
+	target := util.StrToBytes(id)
+	ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+
+		var refID *ast.Node
+		switch n.Type {
+		case ast.NodeBlockRef:
+			refID = n.ChildByType(ast.NodeBlockRefID)
+		case ast.NodeBlockEmbed:
+			refID = n.ChildByType(ast.NodeBlockEmbedID)
+		default:
+			return ast.WalkContinue
+		}
+		if nil != refID && bytes.Equal(bytes.ReplaceAll(refID.Tokens, util.CaretTokens, nil), target) {
+			ret = append(ret, n)
+		}
+		return ast.WalkContinue
+	})
+	return
+}
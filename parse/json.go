@@ -0,0 +1,167 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/util"
+)
+
+// TreeJSONSchema 语法树 JSON 格式版本，格式发生不兼容变更时递增。
+//
+// 版本历史：
+//   - 1：初始版本
+//   - 2：节点增加源码行号 SourceLine，版本 1 的 JSON 仍然可以加载，加载后行号未知
+const TreeJSONSchema = 2
+
+// jsonTree 描述了语法树的 JSON 结构。
+type jsonTree struct {
+	Schema  int       // 格式版本
+	Name    string    `json:",omitempty"`
+	ID      string    `json:",omitempty"`
+	URL     string    `json:",omitempty"`
+	Path    string    `json:",omitempty"`
+	Marks   []string  `json:",omitempty"`
+	Created int64     `json:",omitempty"`
+	Updated int64     `json:",omitempty"`
+	Hash    string    `json:",omitempty"`
+	Options *Options  // 解析选项，不包含 Emoji 映射
+	Root    *jsonNode // 根节点
+}
+
+// nodeFields 用于复用 ast.Node 的字段及其 JSON 标签。
+type nodeFields ast.Node
+
+// jsonNode 描述了节点的 JSON 结构。节点类型使用名称而不是数值保存，这样节点类型常量调整后仍然可以正确加载。
+type jsonNode struct {
+	nodeFields
+
+	Type        string      // 节点类型名称
+	URL         string      `json:",omitempty"`
+	Path        string      `json:",omitempty"`
+	KramdownIAL [][]string  `json:",omitempty"`
+	Children    []*jsonNode `json:",omitempty"`
+}
+
+// MarshalJSON 将语法树序列化为 JSON。
+//
+// 序列化结果包含重新渲染所需的全部节点字段，节点之间的链接关系通过 Children 嵌套表示，脚注引用关系在反序列化时重建。
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	ret := &jsonTree{Schema: TreeJSONSchema, Name: t.Name, ID: t.ID, URL: t.URL, Path: t.Path, Marks: t.Marks,
+		Created: t.Created, Updated: t.Updated, Hash: t.Hash}
+	if nil != t.Context && nil != t.Context.ParseOption {
+		options := *t.Context.ParseOption
+		options.AliasEmoji, options.EmojiAlias = nil, nil
+		ret.Options = &options
+	}
+	if nil != t.Root {
+		ret.Root = toJSONNode(t.Root)
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON 从 MarshalJSON 生成的 JSON 重建语法树，包括节点之间的链接关系和解析上下文。
+func (t *Tree) UnmarshalJSON(data []byte) error {
+	tree := &jsonTree{}
+	if err := json.Unmarshal(data, tree); nil != err {
+		return err
+	}
+	if 1 > tree.Schema || TreeJSONSchema < tree.Schema {
+		return errors.New("unsupported tree JSON schema version " + strconv.Itoa(tree.Schema))
+	}
+	if nil == tree.Root {
+		return errors.New("tree JSON without root node")
+	}
+
+	root, err := fromJSONNode(tree.Root)
+	if nil != err {
+		return err
+	}
+
+	options := NewOptions()
+	if nil != tree.Options {
+		options = tree.Options
+		options.AliasEmoji, options.EmojiAlias = EmojiAliasUnicode, EmojiUnicodeAlias
+	}
+	*t = Tree{Root: root, Name: tree.Name, ID: tree.ID, URL: tree.URL, Path: tree.Path, Marks: tree.Marks,
+		Created: tree.Created, Updated: tree.Updated, Hash: tree.Hash}
+	t.Context = &Context{Tree: t, ParseOption: options}
+	t.linkFootnotesRefs()
+	return nil
+}
+
+// ParseJSON 从 JSON 数据 data 加载语法树，data 需要是 Tree.MarshalJSON 的序列化结果。
+func ParseJSON(data []byte) (ret *Tree, err error) {
+	ret = &Tree{}
+	if err = ret.UnmarshalJSON(data); nil != err {
+		return nil, err
+	}
+	return
+}
+
+func toJSONNode(n *ast.Node) (ret *jsonNode) {
+	ret = &jsonNode{nodeFields: nodeFields(*n), Type: n.Type.String(), URL: n.URL, Path: n.Path, KramdownIAL: n.KramdownIAL}
+	ret.FootnotesRefs = nil // 脚注引用关系在反序列化时重建
+	for c := n.FirstChild; nil != c; c = c.Next {
+		ret.Children = append(ret.Children, toJSONNode(c))
+	}
+	return
+}
+
+func fromJSONNode(jsonNode *jsonNode) (ret *ast.Node, err error) {
+	ret = (*ast.Node)(&jsonNode.nodeFields)
+	ret.Type = ast.Str2NodeType(jsonNode.Type)
+	if 0 > ret.Type {
+		return nil, errors.New("unknown node type [" + jsonNode.Type + "]")
+	}
+	ret.URL, ret.Path, ret.KramdownIAL = jsonNode.URL, jsonNode.Path, jsonNode.KramdownIAL
+	ret.FootnotesRefs = nil
+	for _, child := range jsonNode.Children {
+		c, err := fromJSONNode(child)
+		if nil != err {
+			return nil, err
+		}
+		ret.AppendChild(c)
+	}
+	return
+}
+
+// linkFootnotesRefs 按文档顺序重建脚注定义到脚注引用的关联。
+func (t *Tree) linkFootnotesRefs() {
+	var defs, refs []*ast.Node
+	ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		switch n.Type {
+		case ast.NodeFootnotesDef:
+			defs = append(defs, n)
+		case ast.NodeFootnotesRef:
+			refs = append(refs, n)
+		}
+		return ast.WalkContinue
+	})
+
+	for _, ref := range refs {
+		label := bytes.ReplaceAll(ref.FootnotesRefLabel, util.CaretTokens, nil)
+		for _, def := range defs {
+			if bytes.EqualFold(bytes.ReplaceAll(def.Tokens, util.CaretTokens, nil), label) {
+				def.FootnotesRefs = append(def.FootnotesRefs, ref)
+				break
+			}
+		}
+	}
+}
@@ -80,11 +80,11 @@ func (context *Context) parseLinkRefDef(tokens []byte) []byte {
 	}
 
 	link := context.Tree.newLink(ast.NodeLink, label, destination, title, 1)
-	def := &ast.Node{Type: ast.NodeLinkRefDef, Tokens: label}
+	def := &ast.Node{Type: ast.NodeLinkRefDef, Tokens: label, SourceLine: context.Tip.SourceLine}
 	def.AppendChild(link)
 	defBlock := context.Tip
 	if ast.NodeLinkRefDefBlock != defBlock.Type {
-		defBlock = &ast.Node{Type: ast.NodeLinkRefDefBlock}
+		defBlock = &ast.Node{Type: ast.NodeLinkRefDefBlock, SourceLine: context.Tip.SourceLine}
 	}
 	defBlock.AppendChild(def)
 	context.Tip.Parent.AppendChild(defBlock)
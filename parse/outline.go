@@ -0,0 +1,136 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/html"
+)
+
+// OutlineHeading 描述了大纲中的标题。
+type OutlineHeading struct {
+	ID       string            `json:"id"`       // 标题 ID，优先使用 IAL 中的 id 属性，否则使用 HeadingID 生成的 ID
+	Level    int               `json:"level"`    // 标题级别 1~6
+	Text     string            `json:"text"`     // 标题文本，由 RenderHeadingText 生成
+	Node     *ast.Node         `json:"-"`        // 标题节点
+	Blocks   []*OutlineBlock   `json:"blocks"`   // 标题下的内容块，即标题之后到下一个标题之前的兄弟块
+	Children []*OutlineHeading `json:"children"` // 下级标题
+}
+
+// OutlineBlock 描述了标题下的内容块。
+type OutlineBlock struct {
+	ID   string    `json:"id,omitempty"` // 块 ID，取自 IAL 中的 id 属性
+	Type string    `json:"type"`         // 块类型，比如 NodeParagraph
+	Node *ast.Node `json:"-"`            // 块节点
+}
+
+// Outline 返回文档大纲。
+//
+// 大纲包含文档中所有的标题（包括 Setext 标题以及嵌套在超级块、引述、列表项中的标题），按照文档顺序和标题级别组织为树：
+// 标题是之前最近的更低级别标题的下级标题。
+func (t *Tree) Outline() (ret []*OutlineHeading) {
+	ret = []*OutlineHeading{}
+	var stack []*OutlineHeading
+	ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeHeading != n.Type {
+			return ast.WalkContinue
+		}
+
+		h := &OutlineHeading{ID: blockIALID(n), Level: n.HeadingLevel, Text: RenderHeadingText(n), Node: n,
+			Blocks: []*OutlineBlock{}, Children: []*OutlineHeading{}}
+		if "" == h.ID {
+			h.ID = t.HeadingID(n)
+		}
+		for b := n.Next; nil != b && ast.NodeHeading != b.Type; b = b.Next {
+			if ast.NodeKramdownBlockIAL == b.Type || !b.IsBlock() {
+				continue
+			}
+			h.Blocks = append(h.Blocks, &OutlineBlock{ID: blockIALID(b), Type: b.Type.String(), Node: b})
+		}
+
+		for 0 < len(stack) && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if 0 < len(stack) {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+		} else {
+			ret = append(ret, h)
+		}
+		stack = append(stack, h)
+		return ast.WalkSkipChildren
+	})
+	return
+}
+
+// blockIALID 返回块 n 的 IAL id 属性。某些情况下（比如超级块中第一个块）IAL 没有设置到块节点上，这时从紧随其后的 IAL 节点中读取。
+func blockIALID(n *ast.Node) string {
+	if id := n.IALAttr("id"); "" != id {
+		return id
+	}
+	if next := n.Next; nil != next && ast.NodeKramdownBlockIAL == next.Type {
+		for _, kv := range Tokens2IAL(next.Tokens) {
+			if "id" == kv[0] {
+				return kv[1]
+			}
+		}
+	}
+	return ""
+}
+
+// RenderHeadingText 返回标题 n 的文本，其中的代码、数学公式、加粗和强调会保留为 HTML 标签。
+func RenderHeadingText(n *ast.Node) (ret string) {
+	buf := &bytes.Buffer{}
+	ast.Walk(n, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+
+		switch n.Type {
+		case ast.NodeLinkText, ast.NodeBlockRefText, ast.NodeBlockEmbedText:
+			buf.Write(n.Tokens)
+		case ast.NodeInlineMathContent:
+			buf.WriteString("<span class=\"language-math\">")
+			buf.Write(html.EscapeHTML(n.Tokens))
+			buf.WriteString("</span>")
+		case ast.NodeCodeSpanContent:
+			buf.WriteString("<code>")
+			buf.Write(html.EscapeHTML(n.Tokens))
+			buf.WriteString("</code>")
+		case ast.NodeText:
+			if n.ParentIs(ast.NodeStrong) {
+				buf.WriteString("<strong>")
+				buf.Write(html.EscapeHTML(n.Tokens))
+				buf.WriteString("</strong>")
+			} else if n.ParentIs(ast.NodeEmphasis) {
+				buf.WriteString("<em>")
+				buf.Write(html.EscapeHTML(n.Tokens))
+				buf.WriteString("</em>")
+			} else {
+				if nil != n.Previous && ast.NodeInlineHTML == n.Previous.Type {
+					if !bytes.HasPrefix(n.Previous.Tokens, []byte("</")) {
+						buf.Write(n.Previous.Tokens)
+						buf.Write(html.EscapeHTML(n.Tokens))
+					} else {
+						buf.Write(n.Previous.Tokens)
+						buf.Write(html.EscapeHTML(n.Tokens))
+					}
+				} else {
+					buf.Write(html.EscapeHTML(n.Tokens))
+				}
+			}
+		}
+		return ast.WalkContinue
+	})
+	return buf.String()
+}
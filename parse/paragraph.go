@@ -32,6 +32,7 @@ func paragraphFinalize(p *ast.Node, context *Context) (insertTable bool) {
 	hasReferenceDefs := false
 	for tokens := p.Tokens; 0 < len(tokens) && lex.ItemOpenBracket == tokens[0]; tokens = p.Tokens {
 		if tokens = context.parseLinkRefDef(tokens); nil != tokens {
+			p.SourceLine += bytes.Count(p.Tokens[:len(p.Tokens)-len(tokens)], []byte{lex.ItemNewline})
 			p.Tokens = tokens
 			hasReferenceDefs = true
 			continue
@@ -92,6 +93,7 @@ func paragraphFinalize(p *ast.Node, context *Context) (insertTable bool) {
 							listItem.PrependChild(&ast.Node{Type: ast.NodeText, Tokens: []byte(" ")})
 							listItem.PrependChild(p.FirstChild)
 							subBlock.ID = p.ID
+							subBlock.SourceLine = p.SourceLine
 							subBlock.KramdownIAL = p.KramdownIAL
 							p.InsertAfter(subBlock)
 							p.Unlink()
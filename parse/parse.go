@@ -72,6 +72,7 @@ type Context struct {
 	offset, column, nextNonspace, nextNonspaceColumn, indent int       // 解析时用到的下标、缩进空格数等
 	indented, blank, partiallyConsumedTab, allClosed         bool      // 是否是缩进行、空行等标识
 	lastMatchedContainer                                     *ast.Node // 最后一个匹配的块节点
+	lineNum                                                  int       // 当前行号
 
 	rootIAL *ast.Node // 根节点 kramdown IAL
 }
@@ -209,7 +210,7 @@ func (context *Context) addChild(nodeType ast.NodeType) (ret *ast.Node) {
 		context.finalize(context.Tip) // 注意调用 finalize 会向父节点方向进行迭代
 	}
 
-	ret = &ast.Node{Type: nodeType}
+	ret = &ast.Node{Type: nodeType, SourceLine: context.lineNum}
 	context.Tip.AppendChild(ret)
 	context.Tip = ret
 	return
@@ -237,6 +238,9 @@ type Tree struct {
 	Created int64    // 创建时间
 	Updated int64    // 更新时间
 	Hash    string   // 内容哈希
+
+	headingIDs    map[*ast.Node]string // 标题 ID 缓存
+	headingIDsKey string               // 标题 ID 缓存对应的生成方式
 }
 
 // Options 描述了解析选项。
@@ -295,6 +299,10 @@ type Options struct {
 	Sub bool
 	// GitConflict 设置是否打开 Git 冲突标记支持。
 	GitConflict bool
+	// HeadingIDSlug 设置标题 ID 生成方式，支持 "github"、"gitlab" 和 "pinyin"，为空时使用默认方式。
+	HeadingIDSlug string
+	// HeadingIDSlugFunc 设置自定义标题 ID 生成函数，参数为标题文本，设置后 HeadingIDSlug 不再生效。
+	HeadingIDSlugFunc func(text string) string `json:"-"`
 }
 
 func NewOptions() *Options {
@@ -0,0 +1,68 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+)
+
+// NodePath 返回节点 n 从根节点开始的路径，比如 NodeDocument/NodeList[0]/NodeListItem[1]。
+func NodePath(n *ast.Node) string {
+	var segments []string
+	for ; nil != n; n = n.Parent {
+		if nil == n.Parent {
+			segments = append(segments, n.Type.String())
+			break
+		}
+		i := 0
+		for c := n.Parent.FirstChild; c != n; c = c.Next {
+			i++
+		}
+		segments = append(segments, n.Type.String()+"["+strconv.Itoa(i)+"]")
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return strings.Join(segments, "/")
+}
+
+// NodeLine 返回节点 n 在 Markdown 源码中的行号（从 1 开始），offset 为 n.Tokens 中的字节偏移量。无法确定时返回 0。
+//
+// 行号由最近的块级节点的起始行号加上其中位于 n 之前的换行数得出，仅对解析 Markdown 文本生成的语法树有效。
+func NodeLine(n *ast.Node, offset int) (ret int) {
+	var block *ast.Node
+	for block = n; nil != block && 1 > block.SourceLine; block = block.Parent {
+	}
+	if nil == block {
+		return 0
+	}
+
+	ret = block.SourceLine
+	if block != n {
+		ast.Walk(block, func(c *ast.Node, entering bool) ast.WalkStatus {
+			if c == n {
+				return ast.WalkStop
+			}
+			if entering && c != block {
+				ret += bytes.Count(c.Tokens, []byte{'\n'})
+			}
+			return ast.WalkContinue
+		})
+	}
+	if 0 < offset && offset <= len(n.Tokens) {
+		ret += bytes.Count(n.Tokens[:offset], []byte{'\n'})
+	}
+	return
+}
@@ -29,6 +29,22 @@ func (context *Context) parseTable(paragraph *ast.Node) (retParagraph, retTable
 				tokens = paragraph.Tokens[i+1:]
 			}
 			if table := context.parseTable0(tokens); nil != table {
+				if 0 < paragraph.SourceLine {
+					table.SourceLine = paragraph.SourceLine
+					if 0 < i {
+						table.SourceLine += bytes.Count(paragraph.Tokens[:i+1], []byte{lex.ItemNewline})
+					}
+					// 表头、分隔行、数据行各占一行
+					line := table.SourceLine
+					for tr := table.FirstChild; nil != tr; tr = tr.Next {
+						tr.SourceLine = line
+						if ast.NodeTableHead == tr.Type {
+							tr.FirstChild.SourceLine = line
+							line++
+						}
+						line++
+					}
+				}
 				if 0 < lineCnt {
 					retParagraph = &ast.Node{Type: ast.NodeParagraph, Tokens: paragraph.Tokens[0:i]}
 				}
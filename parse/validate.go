@@ -0,0 +1,427 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"strconv"
+
+	"github.com/sunlightcs/lute/ast"
+)
+
+// Violation 描述了语法树结构违规。
+type Violation struct {
+	Path    string    // 节点路径，比如 NodeDocument/NodeList[0]/NodeParagraph[1]，方括号中为节点在兄弟节点中的下标
+	Node    *ast.Node `json:"-"` // 违规节点
+	Message string    // 违规描述
+}
+
+func (v *Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// 成对出现的标记符，开始标记符是父节点的第一个子节点，结束标记符是父节点的最后一个子节点。
+var closeMarkers = map[ast.NodeType]ast.NodeType{
+	ast.NodeEmA6kOpenMarker:           ast.NodeEmA6kCloseMarker,
+	ast.NodeEmU8eOpenMarker:           ast.NodeEmU8eCloseMarker,
+	ast.NodeStrongA6kOpenMarker:       ast.NodeStrongA6kCloseMarker,
+	ast.NodeStrongU8eOpenMarker:       ast.NodeStrongU8eCloseMarker,
+	ast.NodeCodeSpanOpenMarker:        ast.NodeCodeSpanCloseMarker,
+	ast.NodeStrikethrough1OpenMarker:  ast.NodeStrikethrough1CloseMarker,
+	ast.NodeStrikethrough2OpenMarker:  ast.NodeStrikethrough2CloseMarker,
+	ast.NodeInlineMathOpenMarker:      ast.NodeInlineMathCloseMarker,
+	ast.NodeMathBlockOpenMarker:       ast.NodeMathBlockCloseMarker,
+	ast.NodeCodeBlockFenceOpenMarker:  ast.NodeCodeBlockFenceCloseMarker,
+	ast.NodeYamlFrontMatterOpenMarker: ast.NodeYamlFrontMatterCloseMarker,
+	ast.NodeMark1OpenMarker:           ast.NodeMark1CloseMarker,
+	ast.NodeMark2OpenMarker:           ast.NodeMark2CloseMarker,
+	ast.NodeTagOpenMarker:             ast.NodeTagCloseMarker,
+	ast.NodeSuperBlockOpenMarker:      ast.NodeSuperBlockCloseMarker,
+	ast.NodeSupOpenMarker:             ast.NodeSupCloseMarker,
+	ast.NodeSubOpenMarker:             ast.NodeSubCloseMarker,
+	ast.NodeGitConflictOpenMarker:     ast.NodeGitConflictCloseMarker,
+}
+
+var openMarkers = map[ast.NodeType]ast.NodeType{}
+
+func init() {
+	for open, cl := range closeMarkers {
+		openMarkers[cl] = open
+	}
+}
+
+// Validate 检查语法树结构，返回所有违规。比如列表项不在列表下、表格单元格不在表格行下、开始标记符没有对应的结束标记符等。
+func (t *Tree) Validate() (ret []*Violation) {
+	ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		for _, msg := range violations(n) {
+			ret = append(ret, &Violation{Path: NodePath(n), Node: n, Message: msg})
+		}
+		return ast.WalkContinue
+	})
+	return
+}
+
+func violations(n *ast.Node) (ret []string) {
+	if p := n.Parent; nil != p {
+		if msg := parentViolation(n, p); "" != msg {
+			ret = append(ret, msg)
+		}
+	}
+
+	switch n.Type {
+	case ast.NodeHeading:
+		if 1 > n.HeadingLevel || 6 < n.HeadingLevel {
+			ret = append(ret, "invalid heading level "+strconv.Itoa(n.HeadingLevel))
+		}
+	case ast.NodeList, ast.NodeListItem:
+		if nil == n.ListData {
+			ret = append(ret, "missing list data")
+		}
+	case ast.NodeTable:
+		if nil == n.FirstChild || ast.NodeTableHead != n.FirstChild.Type {
+			ret = append(ret, "table without head")
+		}
+	case ast.NodeEmphasis, ast.NodeStrong, ast.NodeStrikethrough, ast.NodeMark, ast.NodeSup, ast.NodeSub:
+		if isEmptyInline(n) {
+			ret = append(ret, "empty "+n.Type.String())
+		}
+	}
+
+	if nil != n.FirstChild {
+		if cl, ok := closeMarkers[n.FirstChild.Type]; ok && cl != n.LastChild.Type {
+			ret = append(ret, "missing close marker "+cl.String())
+		}
+		if open, ok := openMarkers[n.LastChild.Type]; ok && open != n.FirstChild.Type {
+			ret = append(ret, "missing open marker "+open.String())
+		}
+	}
+	return
+}
+
+// parentViolation 检查节点 n 是否可以作为 p 的子节点。
+func parentViolation(n, p *ast.Node) string {
+	switch n.Type {
+	case ast.NodeListItem:
+		if ast.NodeList != p.Type {
+			return "list item outside list"
+		}
+		return ""
+	case ast.NodeTableHead:
+		if ast.NodeTable != p.Type {
+			return "table head outside table"
+		}
+		return ""
+	case ast.NodeTableRow:
+		if ast.NodeTable != p.Type && ast.NodeTableHead != p.Type {
+			return "table row outside table"
+		}
+		return ""
+	case ast.NodeTableCell:
+		if ast.NodeTableRow != p.Type {
+			return "table cell outside table row"
+		}
+		return ""
+	case ast.NodeFootnotesDef:
+		if ast.NodeFootnotesDefBlock != p.Type {
+			return "footnotes def outside footnotes def block"
+		}
+		return ""
+	}
+
+	switch p.Type {
+	case ast.NodeList:
+		if ast.NodeKramdownBlockIAL != n.Type {
+			return n.Type.String() + " inside list"
+		}
+		return ""
+	case ast.NodeTable, ast.NodeTableHead:
+		return n.Type.String() + " inside table"
+	case ast.NodeTableRow:
+		return n.Type.String() + " inside table row"
+	case ast.NodeFootnotesDefBlock:
+		if ast.NodeKramdownBlockIAL != n.Type {
+			return n.Type.String() + " inside footnotes def block"
+		}
+		return ""
+	}
+
+	if isBlockLevel(n) {
+		if !p.IsContainerBlock() {
+			return "block " + n.Type.String() + " inside " + p.Type.String()
+		}
+	} else if p.IsContainerBlock() && !n.IsMarker() {
+		return "inline " + n.Type.String() + " outside leaf block"
+	}
+	return ""
+}
+
+// Repair 修复语法树结构，返回修复后仍然存在的违规。修复包括：
+//
+//   - 补全缺失的开始或结束标记符
+//   - 将嵌套在叶子块或者行级节点中的块级节点移到该叶子块之后
+//   - 使用列表、表格行、表格、脚注定义块和段落包裹游离的列表项、表格单元格、表格行、脚注定义和行级节点
+//   - 删除空的强调、加粗、删除线、标记、上标和下标
+//   - 合并相邻的文本节点
+func (t *Tree) Repair() []*Violation {
+	t.repairMarkers()
+	t.repairBlocks()
+	t.repairOrphans()
+	t.repairInlines()
+	return t.Validate()
+}
+
+func (t *Tree) repairMarkers() {
+	for _, n := range t.Root.List() {
+		switch n.Type {
+		case ast.NodeHeading:
+			if 1 > n.HeadingLevel {
+				n.HeadingLevel = 1
+			} else if 6 < n.HeadingLevel {
+				n.HeadingLevel = 6
+			}
+		case ast.NodeList, ast.NodeListItem:
+			if nil == n.ListData {
+				n.ListData = defaultListData()
+			}
+		}
+
+		if nil == n.FirstChild {
+			continue
+		}
+		if cl, ok := closeMarkers[n.FirstChild.Type]; ok && cl != n.LastChild.Type {
+			n.AppendChild(&ast.Node{Type: cl, Tokens: n.FirstChild.Tokens, CodeBlockFenceLen: n.FirstChild.CodeBlockFenceLen})
+		}
+		if open, ok := openMarkers[n.LastChild.Type]; ok && open != n.FirstChild.Type {
+			n.PrependChild(&ast.Node{Type: open, Tokens: n.LastChild.Tokens, CodeBlockFenceLen: n.LastChild.CodeBlockFenceLen})
+		}
+	}
+}
+
+// repairBlocks 将嵌套在叶子块或者行级节点中的块级节点移到最近的叶子块之后。
+func (t *Tree) repairBlocks() {
+	lastMoved := map[*ast.Node]*ast.Node{}
+	for _, n := range t.Root.List() {
+		if !isBlockLevel(n) || nil == n.Parent || n.Parent.IsContainerBlock() {
+			continue
+		}
+
+		anchor := n.Parent
+		for nil != anchor.Parent && !anchor.Parent.IsContainerBlock() {
+			anchor = anchor.Parent
+		}
+		if nil == anchor.Parent {
+			continue
+		}
+		prev := lastMoved[anchor]
+		if nil == prev {
+			prev = anchor
+		}
+		prev.InsertAfter(n)
+		lastMoved[anchor] = n
+	}
+}
+
+func (t *Tree) repairOrphans() {
+	for _, n := range t.Root.List() {
+		if nil == n.Parent {
+			continue
+		}
+		p := n.Parent
+
+		switch {
+		case ast.NodeListItem == n.Type && ast.NodeList != p.Type:
+			list := &ast.Node{Type: ast.NodeList, ListData: copyListData(n.ListData)}
+			wrapSiblings(n, list, func(c *ast.Node) bool { return ast.NodeListItem == c.Type })
+			for item := list.FirstChild; nil != item; item = item.Next {
+				if 1 > len(item.ListData.Marker) {
+					// 没有标识符的列表项使用列表的标识符
+					item.ListData = copyListData(list.ListData)
+					item.Tokens = item.ListData.Marker
+				}
+			}
+		case ast.NodeList == p.Type && ast.NodeListItem != n.Type && ast.NodeKramdownBlockIAL != n.Type:
+			item := &ast.Node{Type: ast.NodeListItem, ListData: copyListData(p.ListData)}
+			item.Tokens = item.ListData.Marker
+			if isBlockLevel(n) {
+				n.Wrap(item)
+			} else {
+				paragraph := &ast.Node{Type: ast.NodeParagraph}
+				wrapSiblings(n, paragraph, isInline)
+				paragraph.Wrap(item)
+			}
+		case ast.NodeTableCell == n.Type && ast.NodeTableRow != p.Type:
+			wrapSiblings(n, &ast.Node{Type: ast.NodeTableRow}, func(c *ast.Node) bool { return ast.NodeTableCell == c.Type })
+			repairTableRow(n.Parent)
+		case ast.NodeTableRow == n.Type && ast.NodeTable != p.Type && ast.NodeTableHead != p.Type:
+			table := &ast.Node{Type: ast.NodeTable}
+			wrapSiblings(n, table, func(c *ast.Node) bool { return ast.NodeTableRow == c.Type })
+			repairTable(table)
+		case ast.NodeTable == n.Type:
+			repairTable(n)
+		case ast.NodeFootnotesDef == n.Type && ast.NodeFootnotesDefBlock != p.Type:
+			wrapSiblings(n, &ast.Node{Type: ast.NodeFootnotesDefBlock}, func(c *ast.Node) bool { return ast.NodeFootnotesDef == c.Type })
+		case ast.NodeFootnotesDefBlock == p.Type && ast.NodeFootnotesDef != n.Type && ast.NodeKramdownBlockIAL != n.Type:
+			p.InsertAfter(n)
+		}
+	}
+
+	for _, n := range t.Root.List() {
+		if nil != n.Parent && n.Parent.IsContainerBlock() && isInline(n) {
+			wrapSiblings(n, &ast.Node{Type: ast.NodeParagraph}, isInline)
+		}
+	}
+}
+
+// repairTable 保证表格 table 的第一个子节点是表头，其他子节点都是表格行，表格行中只有单元格。
+func repairTable(table *ast.Node) {
+	anchor := table
+	for c := table.FirstChild; nil != c; {
+		next := c.Next
+		switch c.Type {
+		case ast.NodeTableHead, ast.NodeTableRow:
+		case ast.NodeTableCell:
+			wrapSiblings(c, &ast.Node{Type: ast.NodeTableRow}, func(n *ast.Node) bool { return ast.NodeTableCell == n.Type })
+			next = c.Parent.Next
+		default:
+			// 非表格节点移到表格后
+			anchor.InsertAfter(c)
+			anchor = c
+		}
+		c = next
+	}
+	if nil == table.FirstChild {
+		table.Unlink()
+		return
+	}
+
+	if ast.NodeTableHead != table.FirstChild.Type {
+		head := &ast.Node{Type: ast.NodeTableHead}
+		table.FirstChild.Wrap(head)
+	}
+	head := table.FirstChild
+	if nil == head.FirstChild {
+		head.AppendChild(&ast.Node{Type: ast.NodeTableRow})
+	}
+	cols := 0
+	for c := head.FirstChild.FirstChild; nil != c; c = c.Next {
+		cols++
+	}
+	if len(table.TableAligns) != cols {
+		aligns := make([]int, cols)
+		copy(aligns, table.TableAligns)
+		table.TableAligns = aligns
+	}
+	head.TableAligns = table.TableAligns
+	for row := head.FirstChild; nil != row; row = row.Next {
+		repairTableRowAligns(row, table.TableAligns)
+	}
+	for row := head.Next; nil != row; row = row.Next {
+		repairTableRowAligns(row, table.TableAligns)
+	}
+}
+
+func repairTableRow(row *ast.Node) {
+	if nil != row.Parent && (ast.NodeTable == row.Parent.Type || ast.NodeTableHead == row.Parent.Type) {
+		return
+	}
+	table := &ast.Node{Type: ast.NodeTable}
+	wrapSiblings(row, table, func(c *ast.Node) bool { return ast.NodeTableRow == c.Type })
+	repairTable(table)
+}
+
+func repairTableRowAligns(row *ast.Node, aligns []int) {
+	row.TableAligns = aligns
+	i := 0
+	for c := row.FirstChild; nil != c; c = c.Next {
+		if ast.NodeTableCell != c.Type {
+			cell := &ast.Node{Type: ast.NodeTableCell}
+			c.InsertBefore(cell)
+			cell.AppendChild(c)
+			c = cell
+		}
+		if i < len(aligns) {
+			c.TableCellAlign = aligns[i]
+		}
+		i++
+	}
+}
+
+// repairInlines 删除空的行级节点并合并相邻的文本节点。
+func (t *Tree) repairInlines() {
+	nodes := t.Root.List()
+	for i := len(nodes) - 1; 0 <= i; i-- {
+		n := nodes[i]
+		switch n.Type {
+		case ast.NodeEmphasis, ast.NodeStrong, ast.NodeStrikethrough, ast.NodeMark, ast.NodeSup, ast.NodeSub:
+			if isEmptyInline(n) {
+				n.Unlink()
+			}
+		case ast.NodeText:
+			if nil != n.Parent && nil != n.Next && ast.NodeText == n.Next.Type {
+				n.Tokens = append(n.Tokens, n.Next.Tokens...)
+				n.Next.Unlink()
+			}
+		}
+	}
+}
+
+// wrapSiblings 使用 wrapper 包裹 n 以及 n 之后连续满足 match 的兄弟节点。
+func wrapSiblings(n, wrapper *ast.Node, match func(*ast.Node) bool) {
+	var nodes []*ast.Node
+	for c := n; nil != c && match(c); c = c.Next {
+		nodes = append(nodes, c)
+	}
+	n.InsertBefore(wrapper)
+	for _, c := range nodes {
+		wrapper.AppendChild(c)
+	}
+}
+
+func isEmptyInline(n *ast.Node) bool {
+	for c := n.FirstChild; nil != c; c = c.Next {
+		if !c.IsMarker() && (ast.NodeText != c.Type || 0 < len(c.Tokens)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBlockLevel 判断 n 是否为块级节点，链接引用定义块也是块级节点。
+func isBlockLevel(n *ast.Node) bool {
+	return n.IsBlock() || ast.NodeLinkRefDefBlock == n.Type
+}
+
+func isInline(n *ast.Node) bool {
+	return !isBlockLevel(n) && !n.IsMarker() && !isTableNode(n)
+}
+
+func isTableNode(n *ast.Node) bool {
+	switch n.Type {
+	case ast.NodeTable, ast.NodeTableHead, ast.NodeTableRow, ast.NodeTableCell:
+		return true
+	}
+	return false
+}
+
+func defaultListData() *ast.ListData {
+	return &ast.ListData{Tight: true, BulletChar: '*', Padding: 2, Marker: []byte("*"), Num: -1}
+}
+
+func copyListData(listData *ast.ListData) *ast.ListData {
+	if nil == listData || 1 > len(listData.Marker) {
+		return defaultListData()
+	}
+	ret := *listData
+	ret.Marker = append([]byte{}, listData.Marker...)
+	return &ret
+}
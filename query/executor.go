@@ -0,0 +1,53 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package query
+
+import (
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+// MemExecutor 描述了基于内存的内容块查询执行器，它在给定的语法树集合上执行查询。
+type MemExecutor struct {
+	Trees []*parse.Tree // 待查询的语法树
+}
+
+// NewMemExecutor 使用 trees 创建一个内存查询执行器。
+func NewMemExecutor(trees ...*parse.Tree) *MemExecutor {
+	return &MemExecutor{Trees: trees}
+}
+
+// ExecuteQuery 执行查询脚本 script，按语法树顺序和文档顺序返回匹配的内容块。
+func (e *MemExecutor) ExecuteQuery(script string) (ret []*ast.Node, err error) {
+	q, err := Parse(script)
+	if nil != err {
+		return
+	}
+
+	for _, tree := range e.Trees {
+		ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering || !n.IsBlock() || ast.NodeKramdownBlockIAL == n.Type {
+				return ast.WalkContinue
+			}
+			if q.Match(n) {
+				ret = append(ret, n)
+				if 0 < q.Limit && q.Limit <= len(ret) {
+					return ast.WalkStop
+				}
+			}
+			return ast.WalkContinue
+		})
+		if 0 < q.Limit && q.Limit <= len(ret) {
+			break
+		}
+	}
+	return
+}
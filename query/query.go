@@ -0,0 +1,431 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// Package query 实现了内容块查询嵌入 !{{ script }} 使用的类 SQL 过滤语言。
+//
+// 支持的语法：
+//
+//	SELECT * FROM blocks [WHERE 条件] [LIMIT n]
+//
+// 条件由 AND、OR、NOT 和括号组合，单个条件的形式为“字段 操作符 值”：
+//   - 字段：type（内容块类型）、content（内容文本）、tag（标签）、id（IAL id），其他字段名都视为 IAL 属性名，也可以使用 ial. 前缀
+//   - 操作符：=、!=（或 <>）、LIKE、NOT LIKE，LIKE 支持 % 和 _ 通配符且不区分大小写
+//   - 值：单引号或者双引号括起来的字符串
+//
+// 比如 SELECT * FROM blocks WHERE type = 'h' AND content LIKE '%待办%' LIMIT 10
+package query
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sunlightcs/lute/ast"
+)
+
+// Query 描述了解析后的查询。
+type Query struct {
+	where expr // 过滤条件，为 nil 时匹配所有内容块
+	Limit int  // 结果数量限制，0 表示不限制
+}
+
+// Parse 解析查询脚本 script。
+func Parse(script string) (ret *Query, err error) {
+	tokens, err := lex(script)
+	if nil != err {
+		return
+	}
+
+	p := &parser{tokens: tokens}
+	ret = &Query{}
+	if err = p.expectKeyword("SELECT"); nil != err {
+		return nil, err
+	}
+	if tok := p.next(); "*" != tok.val {
+		return nil, p.errorf(tok, "expected [*]")
+	}
+	if err = p.expectKeyword("FROM"); nil != err {
+		return nil, err
+	}
+	if tok := p.next(); tokenIdent != tok.typ || !strings.EqualFold("blocks", tok.val) {
+		return nil, p.errorf(tok, "expected [blocks]")
+	}
+	if p.acceptKeyword("WHERE") {
+		if ret.where, err = p.parseOr(); nil != err {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		tok := p.next()
+		if tokenNumber != tok.typ {
+			return nil, p.errorf(tok, "expected limit number")
+		}
+		ret.Limit, _ = strconv.Atoi(tok.val)
+	}
+	if tok := p.peek(); tokenEOF != tok.typ {
+		return nil, p.errorf(tok, "unexpected ["+tok.val+"]")
+	}
+	return
+}
+
+// Match 判断内容块 block 是否满足查询条件。
+func (q *Query) Match(block *ast.Node) bool {
+	if nil == q.where {
+		return true
+	}
+	return q.where.eval(block)
+}
+
+type expr interface {
+	eval(block *ast.Node) bool
+}
+
+type andExpr struct{ left, right expr }
+
+func (e *andExpr) eval(block *ast.Node) bool { return e.left.eval(block) && e.right.eval(block) }
+
+type orExpr struct{ left, right expr }
+
+func (e *orExpr) eval(block *ast.Node) bool { return e.left.eval(block) || e.right.eval(block) }
+
+type notExpr struct{ expr expr }
+
+func (e *notExpr) eval(block *ast.Node) bool { return !e.expr.eval(block) }
+
+// condExpr 描述了单个条件：字段 操作符 值。
+type condExpr struct {
+	field string
+	op    string // =、!=、LIKE、NOT LIKE
+	value string
+}
+
+func (e *condExpr) eval(block *ast.Node) bool {
+	var values []string
+	switch e.field {
+	case "type":
+		return e.compare([]string{block.Type.String()}, func(v string) string { return blockType(v) })
+	case "content":
+		values = []string{block.Text()}
+	case "tag":
+		values = tags(block)
+		if 1 > len(values) {
+			return "!=" == e.op || "NOT LIKE" == e.op
+		}
+	case "id":
+		values = []string{block.IALAttr("id")}
+	default:
+		values = []string{block.IALAttr(e.field)}
+	}
+	return e.compare(values, nil)
+}
+
+// compare 判断 values 中是否有值满足条件，对于否定操作符要求所有值都不满足。
+func (e *condExpr) compare(values []string, normalize func(string) string) bool {
+	value := e.value
+	if nil != normalize {
+		value = normalize(value)
+	}
+	switch e.op {
+	case "=", "!=":
+		var matched bool
+		for _, v := range values {
+			if v == value {
+				matched = true
+				break
+			}
+		}
+		return matched == ("=" == e.op)
+	default:
+		var matched bool
+		for _, v := range values {
+			if like(v, value) {
+				matched = true
+				break
+			}
+		}
+		return matched == ("LIKE" == e.op)
+	}
+}
+
+// blockTypeAliases 定义了内容块类型简写。
+var blockTypeAliases = map[string]ast.NodeType{
+	"d":    ast.NodeDocument,
+	"h":    ast.NodeHeading,
+	"p":    ast.NodeParagraph,
+	"l":    ast.NodeList,
+	"i":    ast.NodeListItem,
+	"b":    ast.NodeBlockquote,
+	"c":    ast.NodeCodeBlock,
+	"m":    ast.NodeMathBlock,
+	"t":    ast.NodeTable,
+	"tb":   ast.NodeThematicBreak,
+	"html": ast.NodeHTMLBlock,
+	"s":    ast.NodeSuperBlock,
+	"e":    ast.NodeBlockEmbed,
+	"q":    ast.NodeBlockQueryEmbed,
+}
+
+// blockType 将类型简写或者不带 Node 前缀的类型名转换为节点类型名。
+func blockType(name string) string {
+	if t, ok := blockTypeAliases[strings.ToLower(name)]; ok {
+		return t.String()
+	}
+	if !strings.HasPrefix(name, "Node") {
+		name = "Node" + name
+	}
+	return name
+}
+
+// tags 返回内容块 block 自身包含的标签，子块中的标签不计入。
+func tags(block *ast.Node) (ret []string) {
+	ast.Walk(block, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		if n != block && n.IsBlock() {
+			return ast.WalkSkipChildren
+		}
+		if ast.NodeTag == n.Type {
+			ret = append(ret, n.Text())
+		}
+		return ast.WalkContinue
+	})
+	return
+}
+
+// like 使用 SQL LIKE 语义判断 str 是否匹配 pattern，% 匹配任意个字符，_ 匹配单个字符，不区分大小写。
+func like(str, pattern string) bool {
+	s := []rune(strings.ToLower(str))
+	p := []rune(strings.ToLower(pattern))
+	var si, pi int
+	starSi, starPi := -1, -1
+	for si < len(s) {
+		if pi < len(p) && ('_' == p[pi] || s[si] == p[pi]) {
+			si++
+			pi++
+		} else if pi < len(p) && '%' == p[pi] {
+			starPi, starSi = pi, si
+			pi++
+		} else if -1 != starPi {
+			pi = starPi + 1
+			starSi++
+			si = starSi
+		} else {
+			return false
+		}
+	}
+	for pi < len(p) && '%' == p[pi] {
+		pi++
+	}
+	return pi == len(p)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (ret token) {
+	ret = p.tokens[p.pos]
+	if tokenEOF != ret.typ {
+		p.pos++
+	}
+	return
+}
+
+func (p *parser) isKeyword(tok token, keyword string) bool {
+	return tokenIdent == tok.typ && strings.EqualFold(keyword, tok.val)
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(p.peek(), keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if tok := p.next(); !p.isKeyword(tok, keyword) {
+		return p.errorf(tok, "expected ["+keyword+"]")
+	}
+	return nil
+}
+
+func (p *parser) errorf(tok token, msg string) error {
+	return errors.New("query syntax error at position " + strconv.Itoa(tok.pos) + ": " + msg)
+}
+
+func (p *parser) parseOr() (ret expr, err error) {
+	if ret, err = p.parseAnd(); nil != err {
+		return
+	}
+	for p.acceptKeyword("OR") {
+		var right expr
+		if right, err = p.parseAnd(); nil != err {
+			return
+		}
+		ret = &orExpr{ret, right}
+	}
+	return
+}
+
+func (p *parser) parseAnd() (ret expr, err error) {
+	if ret, err = p.parseNot(); nil != err {
+		return
+	}
+	for p.acceptKeyword("AND") {
+		var right expr
+		if right, err = p.parseNot(); nil != err {
+			return
+		}
+		ret = &andExpr{ret, right}
+	}
+	return
+}
+
+func (p *parser) parseNot() (ret expr, err error) {
+	if p.acceptKeyword("NOT") {
+		if ret, err = p.parseNot(); nil != err {
+			return
+		}
+		return &notExpr{ret}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (ret expr, err error) {
+	tok := p.next()
+	if "(" == tok.val && tokenPunct == tok.typ {
+		if ret, err = p.parseOr(); nil != err {
+			return
+		}
+		if closeTok := p.next(); ")" != closeTok.val {
+			return nil, p.errorf(closeTok, "expected [)]")
+		}
+		return
+	}
+
+	if tokenIdent != tok.typ {
+		return nil, p.errorf(tok, "expected field name")
+	}
+	cond := &condExpr{field: strings.TrimPrefix(tok.val, "ial.")}
+	switch strings.ToLower(cond.field) {
+	case "type", "content", "tag", "id":
+		cond.field = strings.ToLower(cond.field)
+	}
+
+	opTok := p.next()
+	switch {
+	case "=" == opTok.val:
+		cond.op = "="
+	case "!=" == opTok.val || "<>" == opTok.val:
+		cond.op = "!="
+	case p.isKeyword(opTok, "LIKE"):
+		cond.op = "LIKE"
+	case p.isKeyword(opTok, "NOT"):
+		if !p.acceptKeyword("LIKE") {
+			return nil, p.errorf(p.peek(), "expected [LIKE]")
+		}
+		cond.op = "NOT LIKE"
+	default:
+		return nil, p.errorf(opTok, "expected operator")
+	}
+
+	valTok := p.next()
+	if tokenString != valTok.typ && tokenNumber != valTok.typ {
+		return nil, p.errorf(valTok, "expected string value")
+	}
+	cond.value = valTok.val
+	return cond, nil
+}
+
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	typ int
+	val string
+	pos int
+}
+
+func lex(script string) (ret []token, err error) {
+	for i := 0; i < len(script); {
+		c, size := utf8.DecodeRuneInString(script[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case '\'' == c || '"' == c:
+			start := i
+			i += size
+			buf := &strings.Builder{}
+			closed := false
+			for i < len(script) {
+				if script[i] == byte(c) {
+					if i+1 < len(script) && script[i+1] == byte(c) { // 连续两个引号表示转义
+						buf.WriteByte(byte(c))
+						i += 2
+						continue
+					}
+					i++
+					closed = true
+					break
+				}
+				buf.WriteByte(script[i])
+				i++
+			}
+			if !closed {
+				return nil, errors.New("query syntax error at position " + strconv.Itoa(start) + ": unterminated string")
+			}
+			ret = append(ret, token{tokenString, buf.String(), start})
+		case '!' == c || '<' == c:
+			if i+1 < len(script) && ('=' == script[i+1] || ('<' == c && '>' == script[i+1])) {
+				ret = append(ret, token{tokenPunct, script[i : i+2], i})
+				i += 2
+				continue
+			}
+			return nil, errors.New("query syntax error at position " + strconv.Itoa(i) + ": unexpected [" + string(c) + "]")
+		case '=' == c || '*' == c || '(' == c || ')' == c:
+			ret = append(ret, token{tokenPunct, string(c), i})
+			i += size
+		case '0' <= c && '9' >= c:
+			start := i
+			for i < len(script) && '0' <= script[i] && '9' >= script[i] {
+				i++
+			}
+			ret = append(ret, token{tokenNumber, script[start:i], start})
+		case unicode.IsLetter(c) || '_' == c:
+			start := i
+			for i < len(script) {
+				r, s := utf8.DecodeRuneInString(script[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && '_' != r && '-' != r && '.' != r {
+					break
+				}
+				i += s
+			}
+			ret = append(ret, token{tokenIdent, script[start:i], start})
+		default:
+			return nil, errors.New("query syntax error at position " + strconv.Itoa(i) + ": unexpected [" + string(c) + "]")
+		}
+	}
+	ret = append(ret, token{tokenEOF, "", len(script)})
+	return
+}
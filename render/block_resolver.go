@@ -0,0 +1,156 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/util"
+)
+
+// BlockResolver 描述了内容块解析器，用于在渲染时根据内容块 ID 获取被引用的内容块。
+type BlockResolver interface {
+	// ResolveBlock 返回 id 对应的内容块。实现可以直接返回语法树节点 node，也可以返回该内容块的 Markdown 文本 markdown，
+	// 两者都为空时表示内容块不存在（悬空引用）。
+	ResolveBlock(id string) (node *ast.Node, markdown string)
+}
+
+// BlockResolverFunc 用于将普通函数适配为 BlockResolver。
+type BlockResolverFunc func(id string) (node *ast.Node, markdown string)
+
+// ResolveBlock 调用 f(id)。
+func (f BlockResolverFunc) ResolveBlock(id string) (node *ast.Node, markdown string) {
+	return f(id)
+}
+
+// 内容块嵌入展开状态。
+const (
+	blockEmbedResolved = iota // 展开成功
+	blockEmbedDangling        // 被嵌入的内容块不存在
+	blockEmbedCycle           // 出现循环嵌入或者超出最大嵌入深度
+)
+
+// blockRefAnchorTextMaxLen 内容块引用锚文本最大长度（字符数）。
+const blockRefAnchorTextMaxLen = 64
+
+// resolveBlock 使用内容块解析器获取 id 对应的内容块，结果会在当前渲染过程中缓存。内容块不存在时返回 nil。
+func (r *BaseRenderer) resolveBlock(id string) (ret *ast.Node) {
+	if nil == r.BlockResolver {
+		return nil
+	}
+
+	id = strings.ReplaceAll(id, util.Caret, "")
+	if nil == r.resolvedBlocks {
+		r.resolvedBlocks = map[string]*ast.Node{}
+	}
+	ret, ok := r.resolvedBlocks[id]
+	if ok {
+		return
+	}
+
+	ret, markdown := r.BlockResolver.ResolveBlock(id)
+	if nil == ret && "" != markdown {
+		options := parse.NewOptions()
+		if nil != r.Tree.Context && nil != r.Tree.Context.ParseOption {
+			options = r.Tree.Context.ParseOption
+		}
+		ret = parse.Parse("", []byte(markdown), options).Root
+	}
+	if nil != ret && nil == ret.Parent && ast.NodeDocument != ret.Type {
+		// 渲染时部分节点需要访问父节点，所以将游离的节点挂到一个临时文档节点下
+		doc := &ast.Node{Type: ast.NodeDocument}
+		doc.AppendChild(ret)
+	}
+	r.resolvedBlocks[id] = ret
+	return
+}
+
+// resolveBlockRef 解析内容块引用 node。如果该引用没有指定锚文本，则返回由被引用内容块的文本生成的锚文本 anchor，
+// 渲染器直接输出 anchor，不会修改语法树。被引用的内容块不存在时 resolved 为 false。
+func (r *BaseRenderer) resolveBlockRef(node *ast.Node) (anchor string, resolved bool) {
+	id := node.ChildByType(ast.NodeBlockRefID)
+	if nil == id {
+		return
+	}
+	block := r.resolveBlock(id.TokensStr())
+	if nil == block {
+		return
+	}
+
+	resolved = true
+	if nil != node.ChildByType(ast.NodeBlockRefTextTplRenderResult) {
+		return
+	}
+	text := node.ChildByType(ast.NodeBlockRefText)
+	if nil == text || text.Text() != id.TokensStr() {
+		// 用户指定了锚文本
+		return
+	}
+	anchor = blockRefAnchorText(block)
+	return
+}
+
+// renderBlockEmbedContent 展开内容块嵌入 id，返回被嵌入内容块渲染后的 HTML 以及展开状态。
+func (r *BaseRenderer) renderBlockEmbedContent(id string) (content []byte, state int) {
+	id = strings.ReplaceAll(id, util.Caret, "")
+	if r.blockEmbedInStack(id) {
+		return nil, blockEmbedCycle
+	}
+
+	block := r.resolveBlock(id)
+	if nil == block {
+		return nil, blockEmbedDangling
+	}
+
+	content = r.renderEmbeddedHTML(block, id)
+	return content, blockEmbedResolved
+}
+
+// renderEmbeddedHTML 将被嵌入的节点 node 渲染为 HTML，embedKey 会被压入嵌入栈用于检测循环嵌入。
+func (r *BaseRenderer) renderEmbeddedHTML(node *ast.Node, embedKey string) []byte {
+	tree := &parse.Tree{Root: node, Context: r.Tree.Context}
+	renderer := NewHtmlRenderer(tree, r.Options)
+	renderer.BlockResolver = r.BlockResolver
+	renderer.QueryExecutor = r.QueryExecutor
+	renderer.resolvedBlocks = r.resolvedBlocks
+	renderer.blockEmbedStack = append(append([]string{}, r.blockEmbedStack...), embedKey)
+	return renderer.BaseRenderer.Render()
+}
+
+// blockEmbedInStack 判断 embedKey 是否已经在嵌入栈中或者嵌入深度已经超出限制。
+func (r *BaseRenderer) blockEmbedInStack(embedKey string) bool {
+	if len(r.blockEmbedStack) >= r.Options.BlockEmbedMaxDepth {
+		return true
+	}
+	for _, key := range r.blockEmbedStack {
+		if embedKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+func blockRefAnchorText(block *ast.Node) (ret string) {
+	if ast.NodeDocument == block.Type {
+		for c := block.FirstChild; nil != c; c = c.Next {
+			if ast.NodeKramdownBlockIAL != c.Type && ast.NodeYamlFrontMatter != c.Type {
+				block = c
+				break
+			}
+		}
+	}
+
+	ret = strings.TrimSpace(block.Text())
+	ret = strings.Join(strings.Fields(ret), " ")
+	return SubStr(ret, blockRefAnchorTextMaxLen)
+}
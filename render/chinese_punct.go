@@ -0,0 +1,229 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strings"
+	"unicode"
+)
+
+// halfWidthPuncts 是中文之间需要转换为全角的半角标点。
+var halfWidthPuncts = map[rune]rune{',': '，', '.': '。', '!': '！', '?': '？', ':': '：', ';': '；'}
+
+// collapsiblePuncts 是不能重复使用的全角标点，…… 和 —— 本身就是两个字符，不在其中。
+const collapsiblePuncts = "，。！？：；、"
+
+// PunctChange 描述了一处中文标点修正。
+type PunctChange struct {
+	Offset int    // 在文本中的字节偏移
+	From   string // 原文本
+	To     string // 修正后的文本
+}
+
+// ChinesePunctChanges 返回按照中文文案排版指北修正文本 text 中的标点需要做出的修改。
+// https://github.com/sparanoid/chinese-copywriting-guidelines
+//
+// 修正规则如下：
+//   - 中文之间的半角标点 ,.!?:;() 转换为全角
+//   - 全角数字和字母转换为半角
+//   - 重复的标点（比如 ！！！）只保留一个
+//   - 内容包含中文的直双引号和直单引号转换为弯引号 “” ‘’，弯引号的左右方向错误时修正方向
+func ChinesePunctChanges(text []byte) (ret []*PunctChange) {
+	runes := []rune(string(text))
+	length := len(runes)
+	out := make([]string, length) // 各个字符修正后的文本
+	chars := make([]rune, length) // 全角数字和字母转换为半角后的字符，用于判断上下文
+	for i, r := range runes {
+		if ('０' <= r && '９' >= r) || ('Ａ' <= r && 'Ｚ' >= r) || ('ａ' <= r && 'ｚ' >= r) {
+			r -= 0xFEE0
+		}
+		chars[i] = r
+		out[i] = string(r)
+	}
+
+	// 中文之间的半角标点，标点两侧的空格一并去掉
+	for i := 0; i < length; i++ {
+		if _, ok := halfWidthPuncts[chars[i]]; !ok {
+			continue
+		}
+		end := i
+		for end < length {
+			if _, ok := halfWidthPuncts[chars[end]]; !ok {
+				break
+			}
+			end++
+		}
+		prev, next := prevNonSpace(chars, i), nextNonSpace(chars, end)
+		if 0 > prev || !unicode.Is(unicode.Han, chars[prev]) || (length > next && !unicode.Is(unicode.Han, chars[next])) ||
+			strings.Contains(string(chars[i:end]), "..") {
+			// 省略号 ... 无法确定应该转换为 …… 还是 。。。，保持原样
+			i = end - 1
+			continue
+		}
+		for j := prev + 1; j < next; j++ {
+			out[j] = ""
+			if punct, ok := halfWidthPuncts[chars[j]]; ok {
+				out[j] = string(punct)
+			}
+		}
+		i = end - 1
+	}
+
+	// 内容包含中文的括号
+	var opens []int
+	for i := 0; i < length; i++ {
+		switch chars[i] {
+		case '(':
+			opens = append(opens, i)
+		case ')':
+			if 1 > len(opens) {
+				continue
+			}
+			open := opens[len(opens)-1]
+			opens = opens[:len(opens)-1]
+			content := chars[open+1 : i]
+			if !containsHan(content) {
+				continue
+			}
+			prev, next := prevNonSpace(chars, open), nextNonSpace(chars, i+1)
+			if (0 > prev || !unicode.Is(unicode.Han, chars[prev])) && !unicode.Is(unicode.Han, content[0]) {
+				continue
+			}
+			out[open], out[i] = "（", "）"
+			if 0 <= prev && unicode.Is(unicode.Han, chars[prev]) {
+				clearRange(out, prev+1, open)
+			}
+			if length > next && unicode.Is(unicode.Han, chars[next]) {
+				clearRange(out, i+1, next)
+			}
+		}
+	}
+
+	// 引号
+	fixQuotes(chars, out, '"', '“', '”')
+	fixQuotes(chars, out, '\'', '‘', '’')
+	var curlies []int
+	for i, r := range chars {
+		if '“' == r || '”' == r {
+			curlies = append(curlies, i)
+		}
+	}
+	if 0 == len(curlies)%2 {
+		for k, i := range curlies {
+			if 0 == k%2 {
+				out[i] = "“"
+			} else {
+				out[i] = "”"
+			}
+		}
+	}
+
+	// 重复的标点
+	last := ""
+	for i := range out {
+		if "" == out[i] {
+			continue
+		}
+		if out[i] == last && strings.Contains(collapsiblePuncts, out[i]) {
+			out[i] = ""
+			continue
+		}
+		last = out[i]
+	}
+
+	offset := 0
+	for i := 0; i < length; {
+		if out[i] == string(runes[i]) {
+			offset += len(string(runes[i]))
+			i++
+			continue
+		}
+		change := &PunctChange{Offset: offset}
+		for ; i < length && out[i] != string(runes[i]); i++ {
+			change.From += string(runes[i])
+			change.To += out[i]
+		}
+		offset += len(change.From)
+		ret = append(ret, change)
+	}
+	return
+}
+
+// FixChinesePunct 按照 ChinesePunctChanges 的规则修正 tokens 中的标点。
+func FixChinesePunct(tokens []byte) []byte {
+	changes := ChinesePunctChanges(tokens)
+	if 1 > len(changes) {
+		return tokens
+	}
+
+	ret := make([]byte, 0, len(tokens))
+	start := 0
+	for _, change := range changes {
+		ret = append(ret, tokens[start:change.Offset]...)
+		ret = append(ret, change.To...)
+		start = change.Offset + len(change.From)
+	}
+	return append(ret, tokens[start:]...)
+}
+
+// fixQuotes 将 chars 中内容包含中文的直引号 quote 对转换为左引号 left 和右引号 right。
+//
+// 前后都是英文字母或者数字的单引号是撇号（比如 it's），不作为引号。
+func fixQuotes(chars []rune, out []string, quote, left, right rune) {
+	open := -1
+	for i, r := range chars {
+		if quote != r {
+			continue
+		}
+		if '\'' == quote && 0 < i && i+1 < len(chars) && isASCIIAlnum(chars[i-1]) && isASCIIAlnum(chars[i+1]) {
+			continue
+		}
+		if 0 > open {
+			open = i
+			continue
+		}
+		if containsHan(chars[open+1 : i]) {
+			out[open], out[i] = string(left), string(right)
+		}
+		open = -1
+	}
+}
+
+func prevNonSpace(chars []rune, i int) int {
+	for i--; 0 <= i && ' ' == chars[i]; i-- {
+	}
+	return i
+}
+
+func nextNonSpace(chars []rune, i int) int {
+	for ; i < len(chars) && ' ' == chars[i]; i++ {
+	}
+	return i
+}
+
+func clearRange(out []string, from, to int) {
+	for i := from; i < to; i++ {
+		out[i] = ""
+	}
+}
+
+func containsHan(chars []rune) bool {
+	for _, r := range chars {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isASCIIAlnum(r rune) bool {
+	return ('a' <= r && 'z' >= r) || ('A' <= r && 'Z' >= r) || ('0' <= r && '9' >= r)
+}
@@ -12,7 +12,6 @@ package render
 
 import (
 	"bytes"
-	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -27,6 +26,11 @@ import (
 type FormatRenderer struct {
 	*BaseRenderer
 	NodeWriterStack []*bytes.Buffer // 节点输出缓冲栈
+
+	wrapping     bool     // 是否正在渲染需要折行的段落
+	wrapKeeps    [][2]int // 段落输出中不能断行的区间
+	wrapKeepFrom int      // 正在标记的不能断行区间的开始位置
+	wrapBreaks   []int    // 段落输出中硬换行的位置
 }
 
 // NewFormatRenderer 创建一个格式化渲染器。
@@ -151,6 +155,17 @@ func NewFormatRenderer(tree *parse.Tree, options *Options) *FormatRenderer {
 	return ret
 }
 
+// RenderBlock 格式化根节点下相邻的块 blocks（比如块和它的内联属性列表），返回去掉首尾空行后的文本，用于按块格式化。
+func (r *FormatRenderer) RenderBlock(blocks ...*ast.Node) []byte {
+	r.LastOut = lex.ItemNewline
+	r.Writer = &bytes.Buffer{}
+	r.NodeWriterStack = []*bytes.Buffer{r.Writer}
+	for _, block := range blocks {
+		ast.Walk(block, r.renderNode)
+	}
+	return bytes.TrimRight(bytes.TrimLeft(r.Writer.Bytes(), "\n"), " \t\n")
+}
+
 func (r *FormatRenderer) renderGitConflictCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Write(node.Tokens)
@@ -176,8 +191,9 @@ func (r *FormatRenderer) renderGitConflictOpenMarker(node *ast.Node, entering bo
 }
 
 func (r *FormatRenderer) renderGitConflict(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.Newline()
+	r.Newline()
+	if !entering && !r.isLastNode(r.Tree.Root, node) {
+		r.WriteByte(lex.ItemNewline)
 	}
 	return ast.WalkContinue
 }
@@ -499,7 +515,7 @@ func (r *FormatRenderer) renderBackslashContent(node *ast.Node, entering bool) a
 }
 
 func (r *FormatRenderer) renderBackslash(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
+	if entering && r.escapeNeeded(node) {
 		r.WriteByte(lex.ItemBackslash)
 	}
 	return ast.WalkContinue
@@ -572,6 +588,9 @@ func (r *FormatRenderer) renderEmoji(node *ast.Node, entering bool) ast.WalkStat
 
 func (r *FormatRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
 	padding := node.TableCellContentMaxWidth - node.TableCellContentWidth
+	if r.formatStyle().NoTablePadding {
+		padding = 0
+	}
 	if entering {
 		r.WriteByte(lex.ItemPipe)
 		r.WriteByte(lex.ItemSpace)
@@ -605,29 +624,33 @@ func (r *FormatRenderer) renderTableHead(node *ast.Node, entering bool) ast.Walk
 	if !entering {
 		headRow := node.FirstChild
 		for th := headRow.FirstChild; nil != th; th = th.Next {
+			width := th.TableCellContentMaxWidth
+			if r.formatStyle().NoTablePadding {
+				width = 3
+			}
 			align := th.TableCellAlign
 			switch align {
 			case 0:
 				r.WriteString("| -")
-				if padding := th.TableCellContentMaxWidth - 1; 0 < padding {
+				if padding := width - 1; 0 < padding {
 					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
 				}
 				r.WriteByte(lex.ItemSpace)
 			case 1:
 				r.WriteString("| :-")
-				if padding := th.TableCellContentMaxWidth - 2; 0 < padding {
+				if padding := width - 2; 0 < padding {
 					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
 				}
 				r.WriteByte(lex.ItemSpace)
 			case 2:
 				r.WriteString("| :-")
-				if padding := th.TableCellContentMaxWidth - 3; 0 < padding {
+				if padding := width - 3; 0 < padding {
 					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
 				}
 				r.WriteString(": ")
 			case 3:
 				r.WriteString("| -")
-				if padding := th.TableCellContentMaxWidth - 2; 0 < padding {
+				if padding := width - 2; 0 < padding {
 					r.Write(bytes.Repeat([]byte{lex.ItemHyphen}, padding))
 				}
 				r.WriteString(": ")
@@ -735,16 +758,27 @@ func (r *FormatRenderer) renderStrikethrough2CloseMarker(node *ast.Node, enterin
 }
 
 func (r *FormatRenderer) renderLinkTitle(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
-		r.WriteByte(lex.ItemDoublequote)
-		r.Write(node.Tokens)
-		r.WriteByte(lex.ItemDoublequote)
+	if entering && !isLinkRefPart(node) {
+		r.Write(quoteLinkTitle(node.Tokens))
 	}
 	return ast.WalkContinue
 }
 
+// quoteLinkTitle 返回用双引号包裹的链接标题 title，标题中的双引号和反斜杠转义后重新解析才能得到原来的标题。
+func quoteLinkTitle(title []byte) []byte {
+	ret := make([]byte, 0, len(title)+2)
+	ret = append(ret, lex.ItemDoublequote)
+	for i, token := range title {
+		if lex.ItemDoublequote == token || (lex.ItemBackslash == token && (i+1 == len(title) || lex.IsASCIIPunct(title[i+1]))) {
+			ret = append(ret, lex.ItemBackslash)
+		}
+		ret = append(ret, token)
+	}
+	return append(ret, lex.ItemDoublequote)
+}
+
 func (r *FormatRenderer) renderLinkDest(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
+	if entering && !isLinkRefPart(node) {
 		tokens := node.Tokens
 		tokens = r.LinkPath(tokens)
 		r.Write(tokens)
@@ -753,7 +787,7 @@ func (r *FormatRenderer) renderLinkDest(node *ast.Node, entering bool) ast.WalkS
 }
 
 func (r *FormatRenderer) renderLinkSpace(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
+	if entering && !isLinkRefPart(node) {
 		r.WriteByte(lex.ItemSpace)
 	}
 	return ast.WalkContinue
@@ -773,14 +807,16 @@ func (r *FormatRenderer) renderLinkText(node *ast.Node, entering bool) ast.WalkS
 }
 
 func (r *FormatRenderer) renderCloseParen(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
+	if entering && !isLinkRefPart(node) {
 		r.WriteByte(lex.ItemCloseParen)
+		r.wrapKeepEnd()
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderOpenParen(node *ast.Node, entering bool) ast.WalkStatus {
-	if entering {
+	if entering && !isLinkRefPart(node) {
+		r.wrapKeepStart()
 		r.WriteByte(lex.ItemOpenParen)
 	}
 	return ast.WalkContinue
@@ -803,10 +839,28 @@ func (r *FormatRenderer) renderOpenBrace(node *ast.Node, entering bool) ast.Walk
 func (r *FormatRenderer) renderCloseBracket(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.WriteByte(lex.ItemCloseBracket)
+		if link := node.Parent; nil != link && 3 == link.LinkType && !isCollapsedLinkRef(link) {
+			// 链接引用 [text][label]，链接地址和标题由链接引用定义给出
+			r.wrapKeepStart()
+			r.WriteString("[" + util.BytesToStr(link.LinkRefLabel) + "]")
+			r.wrapKeepEnd()
+		}
 	}
 	return ast.WalkContinue
 }
 
+// isLinkRefPart 判断节点 node 是否是链接引用中不需要输出的链接地址部分，比如 (、链接地址和标题。
+func isLinkRefPart(node *ast.Node) bool {
+	return nil != node.Parent && 3 == node.Parent.LinkType
+}
+
+// isCollapsedLinkRef 判断链接引用 link 是否可以写为 [label] 的形式，即链接文本和 label 相同。
+func isCollapsedLinkRef(link *ast.Node) bool {
+	text := link.ChildByType(ast.NodeLinkText)
+	return nil != text && nil != text.Previous && ast.NodeOpenBracket == text.Previous.Type && nil != text.Next && ast.NodeCloseBracket == text.Next.Type &&
+		bytes.Equal(text.Tokens, link.LinkRefLabel)
+}
+
 func (r *FormatRenderer) renderOpenBracket(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.WriteByte(lex.ItemOpenBracket)
@@ -828,18 +882,13 @@ func (r *FormatRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStat
 func (r *FormatRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.LinkTextAutoSpacePrevious(node)
-		if 3 == node.LinkType {
-			text := node.ChildByType(ast.NodeLinkText).Tokens
-			if bytes.Equal(text, node.LinkRefLabel) {
-				r.WriteString("[" + util.BytesToStr(text) + "]")
-			} else {
-				r.WriteString("[" + util.BytesToStr(text) + "][" + util.BytesToStr(node.LinkRefLabel) + "]")
-			}
-			return ast.WalkSkipChildren
-		}
 		if 1 == node.LinkType {
 			dest := node.ChildByType(ast.NodeLinkDest).Tokens
 			r.Write(dest)
+			if title := node.ChildByType(ast.NodeLinkTitle); nil != title {
+				r.WriteByte(lex.ItemSpace)
+				r.Write(quoteLinkTitle(title.Tokens))
+			}
 			return ast.WalkSkipChildren
 		}
 	} else {
@@ -864,7 +913,9 @@ func (r *FormatRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatu
 
 func (r *FormatRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		r.wrapKeepStart()
 		r.Write(node.Tokens)
+		r.wrapKeepEnd()
 	}
 	return ast.WalkContinue
 }
@@ -884,7 +935,21 @@ func (r *FormatRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkS
 }
 
 func (r *FormatRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
-	if !entering {
+	if entering {
+		if 0 < r.paragraphWrapWidth(node) {
+			r.Writer = &bytes.Buffer{}
+			r.NodeWriterStack = append(r.NodeWriterStack, r.Writer)
+			r.wrapping, r.wrapKeeps, r.wrapKeepFrom, r.wrapBreaks = true, nil, -1, nil
+		}
+	} else {
+		if r.wrapping {
+			writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
+			r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
+			r.Writer = r.NodeWriterStack[len(r.NodeWriterStack)-1]
+			r.Write(r.wrapParagraph(node, writer.Bytes(), r.paragraphWrapWidth(node)))
+			r.wrapping = false
+		}
+
 		if !node.ParentIs(ast.NodeTableCell) {
 			if r.withoutKramdownBlockIAL(node) {
 				r.Newline()
@@ -928,9 +993,16 @@ func (r *FormatRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatu
 			tokens = node.Tokens
 		}
 
+		if r.Options.ChinesePunct {
+			tokens = FixChinesePunct(tokens)
+		}
 		if r.Options.FixTermTypo {
 			tokens = r.FixTermTypo(tokens)
 		}
+		if headingNumberText(node) {
+			// 去掉上次格式化时写入的编号
+			tokens = r.trimHeadingNumber(node.Parent, tokens)
+		}
 		if nil == node.Previous && nil != node.Parent.Parent && nil != node.Parent.Parent.ListData && 3 == node.Parent.Parent.ListData.Typ {
 			// 任务列表起始位置使用 `<font>` 标签的预览问题 https://github.com/siyuan-note/siyuan/issues/33
 			if !bytes.HasPrefix(tokens, []byte(" ")) && ' ' != r.LastOut {
@@ -967,14 +1039,11 @@ func (r *FormatRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkS
 
 func (r *FormatRenderer) renderCodeSpanOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemBacktick)
-		if 1 < node.Parent.CodeMarkerLen {
-			r.WriteByte(lex.ItemBacktick)
-			text := util.BytesToStr(node.Next.Tokens)
-			firstc, _ := utf8.DecodeRuneInString(text)
-			if '`' == firstc {
-				r.WriteByte(lex.ItemSpace)
-			}
+		r.wrapKeepStart()
+		marker, pad := codeSpanMarker(node.Parent)
+		r.Write(marker)
+		if pad {
+			r.WriteByte(lex.ItemSpace)
 		}
 	}
 	return ast.WalkContinue
@@ -995,24 +1064,51 @@ func (r *FormatRenderer) renderCodeSpanContent(node *ast.Node, entering bool) as
 
 func (r *FormatRenderer) renderCodeSpanCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		if 1 < node.Parent.CodeMarkerLen {
-			text := util.BytesToStr(node.Previous.Tokens)
-			lastc, _ := utf8.DecodeLastRuneInString(text)
-			if '`' == lastc {
-				r.WriteByte(lex.ItemSpace)
-			}
-			r.WriteByte(lex.ItemBacktick)
+		marker, pad := codeSpanMarker(node.Parent)
+		if pad {
+			r.WriteByte(lex.ItemSpace)
 		}
-		r.WriteByte(lex.ItemBacktick)
+		r.Write(marker)
+		r.wrapKeepEnd()
 	}
 	return ast.WalkContinue
 }
 
+// codeSpanMarker 返回行级代码 codeSpan 的标记符以及内容两侧是否需要加一个空格。
+//
+// 标记符要比内容中最长的连续反引号更长，内容以反引号开头或结尾时需要用空格和标记符隔开。
+func codeSpanMarker(codeSpan *ast.Node) (marker []byte, pad bool) {
+	var content []byte
+	if contentNode := codeSpan.ChildByType(ast.NodeCodeSpanContent); nil != contentNode {
+		content = contentNode.Tokens
+	}
+
+	length, run := codeSpan.CodeMarkerLen, 0
+	for _, token := range content {
+		if lex.ItemBacktick != token {
+			run = 0
+			continue
+		}
+		if run++; run >= length {
+			length = run + 1
+		}
+	}
+	if 1 > length {
+		length = 1
+	}
+
+	if last := len(content) - 1; 0 <= last {
+		pad = lex.ItemBacktick == content[0] || lex.ItemBacktick == content[last]
+	}
+	return bytes.Repeat([]byte{lex.ItemBacktick}, length), pad
+}
+
 func (r *FormatRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
 func (r *FormatRenderer) renderInlineMathOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		r.wrapKeepStart()
 		r.WriteByte(lex.ItemDollar)
 	}
 	return ast.WalkContinue
@@ -1028,6 +1124,7 @@ func (r *FormatRenderer) renderInlineMathContent(node *ast.Node, entering bool)
 func (r *FormatRenderer) renderInlineMathCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.WriteByte(lex.ItemDollar)
+		r.wrapKeepEnd()
 	}
 	return ast.WalkContinue
 }
@@ -1069,7 +1166,7 @@ func (r *FormatRenderer) renderMathBlock(node *ast.Node, entering bool) ast.Walk
 func (r *FormatRenderer) renderCodeBlockCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.Newline()
-		r.Write(node.Tokens)
+		r.Write(r.codeBlockFence(node.Parent, node.Tokens))
 		r.Newline()
 		if !r.isLastNode(r.Tree.Root, node) {
 			if r.withoutKramdownBlockIAL(node.Parent) {
@@ -1097,7 +1194,7 @@ func (r *FormatRenderer) renderCodeBlockInfoMarker(node *ast.Node, entering bool
 
 func (r *FormatRenderer) renderCodeBlockOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.Write(node.Tokens)
+		r.Write(r.codeBlockFence(node.Parent, node.Tokens))
 	}
 	return ast.WalkContinue
 }
@@ -1106,10 +1203,11 @@ func (r *FormatRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.Walk
 	if entering {
 		r.Newline()
 		if !node.IsFencedCodeBlock {
-			r.Write(bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+			fence := r.codeBlockFence(node, bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+			r.Write(fence)
 			r.WriteByte(lex.ItemNewline)
 			r.Write(node.FirstChild.Tokens)
-			r.Write(bytes.Repeat([]byte{lex.ItemBacktick}, 3))
+			r.Write(fence)
 			r.Newline()
 			if !r.isLastNode(r.Tree.Root, node) {
 				if r.withoutKramdownBlockIAL(node) {
@@ -1133,28 +1231,28 @@ func (r *FormatRenderer) renderEmphasis(node *ast.Node, entering bool) ast.WalkS
 
 func (r *FormatRenderer) renderEmAsteriskOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemAsterisk)
+		r.WriteString(r.emphasisMarker(node.Parent, "*"))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderEmAsteriskCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemAsterisk)
+		r.WriteString(r.emphasisMarker(node.Parent, "*"))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderEmUnderscoreOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemUnderscore)
+		r.WriteString(r.emphasisMarker(node.Parent, "_"))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderEmUnderscoreCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteByte(lex.ItemUnderscore)
+		r.WriteString(r.emphasisMarker(node.Parent, "_"))
 	}
 	return ast.WalkContinue
 }
@@ -1170,28 +1268,28 @@ func (r *FormatRenderer) renderStrong(node *ast.Node, entering bool) ast.WalkSta
 
 func (r *FormatRenderer) renderStrongA6kOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("**")
+		r.WriteString(r.emphasisMarker(node.Parent, "**"))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderStrongA6kCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("**")
+		r.WriteString(r.emphasisMarker(node.Parent, "**"))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderStrongU8eOpenMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("__")
+		r.WriteString(r.emphasisMarker(node.Parent, "__"))
 	}
 	return ast.WalkContinue
 }
 
 func (r *FormatRenderer) renderStrongU8eCloseMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		r.WriteString("__")
+		r.WriteString(r.emphasisMarker(node.Parent, "__"))
 	}
 	return ast.WalkContinue
 }
@@ -1256,12 +1354,15 @@ func (r *FormatRenderer) renderBlockquoteMarker(node *ast.Node, entering bool) a
 
 func (r *FormatRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		if !node.HeadingSetext {
+		if !r.headingSetext(node) {
 			r.Write(bytes.Repeat([]byte{lex.ItemCrosshatch}, node.HeadingLevel))
 			r.WriteByte(lex.ItemSpace)
 		}
+		if number := r.headingNumber(node); "" != number {
+			r.WriteString(number + " ")
+		}
 	} else {
-		if node.HeadingSetext {
+		if r.headingSetext(node) {
 			r.WriteByte(lex.ItemNewline)
 			contentLen := r.setextHeadingLen(node)
 			if 1 == node.HeadingLevel {
@@ -1327,10 +1428,7 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 	} else {
 		writer := r.NodeWriterStack[len(r.NodeWriterStack)-1]
 		r.NodeWriterStack = r.NodeWriterStack[:len(r.NodeWriterStack)-1]
-		indent := len(node.Marker) + 1
-		if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
-			indent++
-		}
+		indent := r.listItemIndent(node)
 		indentSpaces := bytes.Repeat([]byte{lex.ItemSpace}, indent)
 		indentedLines := bytes.Buffer{}
 		buf := writer.Bytes()
@@ -1350,11 +1448,7 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 		}
 
 		listItemBuf := bytes.Buffer{}
-		if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
-			listItemBuf.WriteString(strconv.Itoa(node.Num) + string(node.ListData.Delimiter))
-		} else {
-			listItemBuf.Write(node.Marker)
-		}
+		listItemBuf.Write(r.listItemMarker(node))
 		listItemBuf.WriteByte(lex.ItemSpace)
 		buf = append(listItemBuf.Bytes(), buf...)
 		if node.ParentIs(ast.NodeTableCell) {
@@ -1380,6 +1474,7 @@ func (r *FormatRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkS
 
 func (r *FormatRenderer) renderTaskListItemMarker(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
+		r.wrapKeepStart()
 		r.WriteByte(lex.ItemOpenBracket)
 		if node.TaskListItemChecked {
 			r.WriteByte('X')
@@ -1387,6 +1482,7 @@ func (r *FormatRenderer) renderTaskListItemMarker(node *ast.Node, entering bool)
 			r.WriteByte(lex.ItemSpace)
 		}
 		r.WriteByte(lex.ItemCloseBracket)
+		r.wrapKeepEnd()
 	}
 	return ast.WalkContinue
 }
@@ -1396,7 +1492,11 @@ func (r *FormatRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.
 		if node.ParentIs(ast.NodeTableCell) {
 			r.WriteString("<hr/>")
 		} else {
-			r.WriteString("---")
+			if thematicBreak := r.formatStyle().ThematicBreak; "" != thematicBreak {
+				r.WriteString(thematicBreak)
+			} else {
+				r.WriteString("---")
+			}
 			if r.withoutKramdownBlockIAL(node) {
 				r.WriteByte(lex.ItemNewline)
 				r.WriteByte(lex.ItemNewline)
@@ -1408,12 +1508,16 @@ func (r *FormatRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.
 
 func (r *FormatRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		if !r.Options.SoftBreak2HardBreak {
-			r.WriteString("\\\n")
+		if !r.Options.SoftBreak2HardBreak || r.wrapping {
+			// 折行时软换行会被合并，所以硬换行需要使用反斜杠保留
+			r.WriteByte(lex.ItemBackslash)
+			r.wrapHardBreak()
+			r.WriteByte(lex.ItemNewline)
 		} else {
 			if node.ParentIs(ast.NodeTableCell) {
 				r.WriteString("<br/>")
 			} else {
+				r.wrapHardBreak()
 				r.WriteByte(lex.ItemNewline)
 			}
 		}
@@ -0,0 +1,232 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/lex"
+)
+
+// 标题样式。
+const (
+	HeadingStylePreserve = "preserve" // 保持原样
+	HeadingStyleATX      = "atx"      // # 标题
+	HeadingStyleSetext   = "setext"   // 一、二级标题使用 === 和 --- 下划线，其他级别使用 ATX
+)
+
+// 有序列表编号方式。
+const (
+	OrderedListNumberingSequential = "sequential" // 从起始序号开始递增
+	OrderedListNumberingOne        = "one"        // 全部为 1
+	OrderedListNumberingPreserve   = "preserve"   // 保持原序号
+)
+
+// 转义方式。
+const (
+	EscapePreserve = "preserve" // 保持原有的反斜杠转义
+	EscapeMinimal  = "minimal"  // 去掉不会产生歧义的字符（比如 , ; ?）前的反斜杠
+)
+
+// escapeUnneeded 是在任何位置都不会产生 Markdown 语法的 ASCII 标点，这些字符前的反斜杠转义可以去掉。
+//
+// @ 和 / 不在其中：foo\@bar.com 去掉转义后是 GFM 邮件自动链接，<\/b> 去掉转义后是 HTML 标签。
+const escapeUnneeded = "\"%',;?"
+
+// FormatStyle 描述了格式化输出的样式，字段为空时保持原样。
+type FormatStyle struct {
+	// BulletMarkers 设置各层无序列表的标记符，比如 "*-+" 表示第一层使用 *，第二层使用 -，第三层使用 +，更深的层级循环使用。
+	BulletMarkers string
+	// EmphasisMarker 设置强调标记符，支持 "*" 和 "_"。使用 "_" 时单词内部的强调仍然使用 "*"。
+	EmphasisMarker string
+	// StrongMarker 设置加粗标记符，支持 "**" 和 "__"。使用 "__" 时单词内部的加粗仍然使用 "**"。
+	StrongMarker string
+	// HeadingStyle 设置标题样式，支持 "preserve"、"atx" 和 "setext"，默认为 "preserve"。
+	HeadingStyle string
+	// OrderedListNumbering 设置有序列表编号方式，支持 "sequential"、"one" 和 "preserve"，默认为 "sequential"。
+	OrderedListNumbering string
+	// FenceChar 设置代码块围栏字符，支持 "`" 和 "~"。
+	FenceChar string
+	// FenceLength 设置代码块围栏长度，不能小于 3。代码中有同样长度的围栏时会自动加长。
+	FenceLength int
+	// ThematicBreak 设置分隔线，默认为 "---"。
+	ThematicBreak string
+	// NoTablePadding 设置是否不使用空格填充表格单元格，默认为 false，即填充空格以对齐各列。
+	NoTablePadding bool
+	// Escape 设置转义方式，支持 "preserve" 和 "minimal"，默认为 "preserve"。
+	Escape string
+}
+
+// NewFormatStyle 创建一个默认的格式化输出样式。
+func NewFormatStyle() *FormatStyle {
+	return &FormatStyle{
+		HeadingStyle:         HeadingStylePreserve,
+		OrderedListNumbering: OrderedListNumberingSequential,
+		ThematicBreak:        "---",
+		Escape:               EscapePreserve,
+	}
+}
+
+var defaultFormatStyle = NewFormatStyle()
+
+func (r *FormatRenderer) formatStyle() *FormatStyle {
+	if nil == r.Options.FormatStyle {
+		return defaultFormatStyle
+	}
+	return r.Options.FormatStyle
+}
+
+// listItemMarker 返回列表项 listItem 的标记符，有序列表项包含分隔符。
+func (r *FormatRenderer) listItemMarker(listItem *ast.Node) []byte {
+	if 1 == listItem.ListData.Typ || (3 == listItem.ListData.Typ && 0 == listItem.ListData.BulletChar) {
+		switch r.formatStyle().OrderedListNumbering {
+		case OrderedListNumberingOne:
+			return []byte("1" + string(listItem.ListData.Delimiter))
+		case OrderedListNumberingPreserve:
+			return append(append([]byte{}, listItem.Marker...), listItem.ListData.Delimiter)
+		}
+		return []byte(strconv.Itoa(listItem.Num) + string(listItem.ListData.Delimiter))
+	}
+
+	if "" == r.formatStyle().BulletMarkers || nil == listItem.Parent {
+		return listItem.Marker
+	}
+	return []byte{r.bulletListMarker(listItem.Parent)}
+}
+
+// bulletListMarker 返回无序列表 list 按照 BulletMarkers 使用的标记符。
+//
+// 和前一个相邻列表的标记符相同时换用其他标记符，否则重新解析时两个列表会合并为一个松散列表。
+func (r *FormatRenderer) bulletListMarker(list *ast.Node) byte {
+	markers := r.formatStyle().BulletMarkers
+	depth := 0
+	for p := list; nil != p; p = p.Parent {
+		if ast.NodeList == p.Type && 0 != p.BulletChar {
+			depth++
+		}
+	}
+	if 1 > depth {
+		depth = 1
+	}
+	ret := markers[(depth-1)%len(markers)]
+	if prev := list.Previous; nil != prev && ast.NodeList == prev.Type && 0 != prev.BulletChar && ret == r.bulletListMarker(prev) {
+		for _, marker := range []byte(markers + "-*+") {
+			if ret != marker {
+				ret = marker
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// listItemIndent 返回列表项 listItem 内容的缩进宽度。
+func (r *FormatRenderer) listItemIndent(listItem *ast.Node) int {
+	return len(r.listItemMarker(listItem)) + 1
+}
+
+// headingSetext 判断标题 heading 是否使用 Setext 样式输出。
+func (r *FormatRenderer) headingSetext(heading *ast.Node) bool {
+	switch r.formatStyle().HeadingStyle {
+	case HeadingStyleATX:
+		return false
+	case HeadingStyleSetext:
+		return 2 >= heading.HeadingLevel && !heading.ParentIs(ast.NodeTableCell)
+	}
+	return heading.HeadingSetext
+}
+
+// emphasisMarker 返回强调（或者加粗）节点 node 的标记符，original 为原标记符。
+func (r *FormatRenderer) emphasisMarker(node *ast.Node, original string) string {
+	marker := r.formatStyle().EmphasisMarker
+	if ast.NodeStrong == node.Type {
+		marker = r.formatStyle().StrongMarker
+	}
+	if "" == marker {
+		return original
+	}
+	if '_' == marker[0] && isIntraword(node) {
+		// 下划线不能用于单词内部的强调
+		return strings.Repeat("*", len(marker))
+	}
+	return marker
+}
+
+// isIntraword 判断节点 node 前后是否紧挨着字母或者数字。
+func isIntraword(node *ast.Node) bool {
+	if text := node.PreviousNodeText(); "" != text {
+		if lastc, _ := utf8.DecodeLastRuneInString(text); unicode.IsLetter(lastc) || unicode.IsDigit(lastc) {
+			return true
+		}
+	}
+	if text := node.NextNodeText(); "" != text {
+		if firstc, _ := utf8.DecodeRuneInString(text); unicode.IsLetter(firstc) || unicode.IsDigit(firstc) {
+			return true
+		}
+	}
+	return false
+}
+
+// codeBlockFence 返回代码块 codeBlock 的围栏，original 为原围栏，缩进代码块的原围栏为 ```。
+func (r *FormatRenderer) codeBlockFence(codeBlock *ast.Node, original []byte) []byte {
+	style := r.formatStyle()
+	if "" == style.FenceChar && 1 > style.FenceLength {
+		return original
+	}
+
+	char := original[0]
+	if "" != style.FenceChar {
+		char = style.FenceChar[0]
+	}
+	if lex.ItemBacktick == char && bytes.ContainsRune(codeBlock.CodeBlockInfo, '`') {
+		// 反引号围栏的信息字符串中不能出现反引号
+		char = lex.ItemTilde
+	}
+	length := len(original)
+	if 0 < style.FenceLength {
+		length = style.FenceLength
+	}
+	if 3 > length {
+		length = 3
+	}
+
+	// 围栏要比代码中同样字符的行首围栏更长
+	var code []byte
+	if code = codeBlock.FirstChild.Tokens; codeBlock.IsFencedCodeBlock {
+		if codeNode := codeBlock.ChildByType(ast.NodeCodeBlockCode); nil != codeNode {
+			code = codeNode.Tokens
+		}
+	}
+	for _, line := range bytes.Split(code, []byte{lex.ItemNewline}) {
+		line = bytes.TrimLeft(line, " ")
+		n := 0
+		for n < len(line) && char == line[n] {
+			n++
+		}
+		if n >= length {
+			length = n + 1
+		}
+	}
+	return bytes.Repeat([]byte{char}, length)
+}
+
+// escapeNeeded 判断反斜杠转义节点 backslash 的反斜杠是否需要输出。
+func (r *FormatRenderer) escapeNeeded(backslash *ast.Node) bool {
+	if EscapeMinimal != r.formatStyle().Escape || nil == backslash.FirstChild || 1 != len(backslash.FirstChild.Tokens) {
+		return true
+	}
+	return !strings.ContainsRune(escapeUnneeded, rune(backslash.FirstChild.Tokens[0]))
+}
@@ -0,0 +1,248 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/lex"
+)
+
+// 折行时片段之间的分隔方式。
+const (
+	wrapSepSpace = ' '  // 空格分隔，可以在此断行
+	wrapSepNone  = 0    // 直接相连，可以在此断行（中日韩文字之间）
+	wrapSepBreak = '\n' // 硬换行，必须在此断行
+)
+
+// 行首禁则：不能出现在行首的标点。
+const kinsokuNotAtLineStart = "，。、；：！？）」』】》〉〕｝］〗〙〛”’…—·～％‰゜ー々ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ"
+
+// 行尾禁则：不能出现在行尾的标点。
+const kinsokuNotAtLineEnd = "（「『【《〈〔｛［〖〘〚“‘"
+
+// unsafeLineStartRegexp 用于判断片段出现在行首时是否会被解析为块，比如列表项、标题、引述。
+var unsafeLineStartRegexp = regexp.MustCompile("^(#{1,6}|[-+*]|\\d{1,9}[.)]|=+|[-*_]{3,}|>.*|```.*|~~~.*|<.*|\\$\\$.*|\\[\\^[^\\]]+\\]:.*|\\{:.*)$")
+
+// wrapPiece 描述了折行时不可再分割的片段。
+type wrapPiece struct {
+	text []byte
+	sep  byte // 和前一个片段的分隔方式
+}
+
+// RuneWidth 返回字符 r 的显示宽度，中日韩字符和全角字符宽度为 2，其他字符为 1。
+func RuneWidth(r rune) int {
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) ||
+		(0x3000 <= r && 0x303F >= r) || (0xFF01 <= r && 0xFF60 >= r) || (0xFFE0 <= r && 0xFFE6 >= r) {
+		return 2
+	}
+	return 1
+}
+
+// TextWidth 返回文本 text 的显示宽度。
+func TextWidth(text []byte) (ret int) {
+	for _, r := range string(text) {
+		ret += RuneWidth(r)
+	}
+	return
+}
+
+// paragraphWrapWidth 返回段落 paragraph 的折行宽度（已经减去列表项缩进和引述标记符的宽度），返回 0 时不折行。
+func (r *FormatRenderer) paragraphWrapWidth(paragraph *ast.Node) int {
+	if paragraph.ParentIs(ast.NodeTableCell) {
+		return 0
+	}
+	if r.Options.FormatUnwrap {
+		return math.MaxInt32
+	}
+	if 1 > r.Options.FormatWrapWidth {
+		return 0
+	}
+
+	width := r.Options.FormatWrapWidth
+	for p := paragraph.Parent; nil != p; p = p.Parent {
+		switch p.Type {
+		case ast.NodeBlockquote:
+			width -= 2
+		case ast.NodeListItem:
+			width -= r.listItemIndent(p)
+		case ast.NodeFootnotesDef:
+			width -= 4
+		}
+	}
+	if 1 > width {
+		width = 1
+	}
+	return width
+}
+
+// wrapKeepStart 标记段落输出中不能断行区间的开始位置，比如代码、数学公式和链接地址。
+func (r *FormatRenderer) wrapKeepStart() {
+	if r.wrapping {
+		r.wrapKeepFrom = r.Writer.Len()
+	}
+}
+
+// wrapKeepEnd 标记段落输出中不能断行区间的结束位置。
+func (r *FormatRenderer) wrapKeepEnd() {
+	if r.wrapping && 0 <= r.wrapKeepFrom {
+		r.wrapKeeps = append(r.wrapKeeps, [2]int{r.wrapKeepFrom, r.Writer.Len()})
+		r.wrapKeepFrom = -1
+	}
+}
+
+// wrapHardBreak 标记段落输出中硬换行的位置，需要在硬换行输出换行符之前调用。
+func (r *FormatRenderer) wrapHardBreak() {
+	if r.wrapping {
+		r.wrapBreaks = append(r.wrapBreaks, r.Writer.Len())
+	}
+}
+
+// wrapParagraph 将段落 paragraph 的输出 buf 按宽度 width 重新折行。
+func (r *FormatRenderer) wrapParagraph(paragraph *ast.Node, buf []byte, width int) []byte {
+	pieces := r.wrapPieces(buf)
+	if 1 < len(pieces) && nil != paragraph.FirstChild && ast.NodeTaskListItemMarker == paragraph.FirstChild.Type {
+		// 任务列表项标记符和后面的文本不能断开
+		pieces[1].text = append(append(pieces[0].text, lex.ItemSpace), pieces[1].text...)
+		pieces = pieces[1:]
+	}
+
+	ret := &bytes.Buffer{}
+	lineWidth := 0
+	for i, piece := range pieces {
+		pieceWidth := TextWidth(piece.text)
+		if 0 < i {
+			sepWidth := 0
+			if wrapSepSpace == piece.sep {
+				sepWidth = 1
+			}
+			if wrapSepBreak == piece.sep ||
+				(width < lineWidth+sepWidth+pieceWidth && 0 < lineWidth && !bytes.HasSuffix(ret.Bytes(), []byte{lex.ItemBackslash}) && !unsafeLineStartRegexp.Match(piece.text)) {
+				ret.WriteByte(lex.ItemNewline)
+				lineWidth = 0
+			} else if wrapSepSpace == piece.sep {
+				ret.WriteByte(lex.ItemSpace)
+				lineWidth++
+			}
+		}
+		ret.Write(piece.text)
+		lineWidth += pieceWidth
+	}
+	return ret.Bytes()
+}
+
+// wrapPieces 将段落的输出 buf 切分为折行片段。
+//
+// 空白处和中日韩文字之间可以断行，但是不能在不能断行区间内、行首禁则标点之前和行尾禁则标点之后断行。
+func (r *FormatRenderer) wrapPieces(buf []byte) (ret []*wrapPiece) {
+	var word []byte // 当前单词
+	var keep []bool // 当前单词中各个字节是否在不能断行区间内（不包括区间开始位置）
+	var sep byte    // 当前单词和前一个单词的分隔方式
+	softBreak := false
+	flush := func() {
+		if 0 == len(word) {
+			return
+		}
+		if softBreak && wrapSepSpace != sep && 0 < len(ret) {
+			// 中日韩文字之间的软换行直接合并
+			last, _ := utf8.DecodeLastRune(ret[len(ret)-1].text)
+			first, _ := utf8.DecodeRune(word)
+			if isCJKWrapRune(last) && isCJKWrapRune(first) {
+				sep = wrapSepNone
+			} else {
+				sep = wrapSepSpace
+			}
+		}
+		ret = append(ret, splitWrapWord(word, keep, sep)...)
+		word, keep, sep, softBreak = nil, nil, wrapSepNone, false
+	}
+
+	keeps, breaks := r.wrapKeeps, map[int]bool{}
+	for _, b := range r.wrapBreaks {
+		breaks[b] = true
+	}
+	for i := 0; i < len(buf); i++ {
+		if 0 < len(keeps) && i == keeps[0][0] {
+			end := keeps[0][1]
+			keeps = keeps[1:]
+			if end > i {
+				for j := i; j < end; j++ {
+					c := buf[j]
+					if lex.ItemNewline == c {
+						c = lex.ItemSpace
+					}
+					word = append(word, c)
+					keep = append(keep, j > i)
+				}
+				i = end - 1
+				continue
+			}
+		}
+
+		switch c := buf[i]; c {
+		case lex.ItemNewline:
+			flush()
+			if breaks[i] {
+				sep = wrapSepBreak
+			} else if wrapSepBreak != sep {
+				softBreak = true
+			}
+		case lex.ItemSpace, lex.ItemTab:
+			flush()
+			if wrapSepBreak != sep {
+				sep = wrapSepSpace
+			}
+		default:
+			word = append(word, c)
+			keep = append(keep, false)
+		}
+	}
+	flush()
+	return
+}
+
+// splitWrapWord 在单词 word 内部的中日韩文字处切分出折行片段。
+func splitWrapWord(word []byte, keep []bool, sep byte) (ret []*wrapPiece) {
+	start := 0
+	var prev rune
+	for i := 0; i < len(word); {
+		r, size := utf8.DecodeRune(word[i:])
+		if 0 < i && !keep[i] && canBreakBetween(prev, r) {
+			ret = append(ret, &wrapPiece{text: word[start:i], sep: sep})
+			start, sep = i, wrapSepNone
+		}
+		prev = r
+		i += size
+	}
+	return append(ret, &wrapPiece{text: word[start:], sep: sep})
+}
+
+// canBreakBetween 判断是否可以在相邻的字符 a 和 b 之间断行。
+func canBreakBetween(a, b rune) bool {
+	if !isCJKWrapRune(a) && !isCJKWrapRune(b) {
+		return false
+	}
+	if (utf8.RuneSelf > a && !unicode.IsLetter(a) && !unicode.IsDigit(a)) || (utf8.RuneSelf > b && !unicode.IsLetter(b) && !unicode.IsDigit(b)) {
+		// 不能在 Markdown 标记符（比如 **、[、`）旁边断行，否则可能破坏行内元素
+		return false
+	}
+	return !strings.ContainsRune(kinsokuNotAtLineEnd, a) && !strings.ContainsRune(kinsokuNotAtLineStart, b)
+}
+
+func isCJKWrapRune(r rune) bool {
+	return 2 == RuneWidth(r) || strings.ContainsRune(kinsokuNotAtLineStart, r) || strings.ContainsRune(kinsokuNotAtLineEnd, r)
+}
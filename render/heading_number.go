@@ -0,0 +1,153 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sunlightcs/lute/ast"
+)
+
+// 标题编号样式。
+const (
+	HeadingNumberStyleDecimal = "decimal" // 1、1.1、1.1.2
+	HeadingNumberStyleChinese = "chinese" // 第一章、1.1、1.1.2
+)
+
+// 标题开头已有的编号，用于刷新编号。
+var (
+	headingNumberDecimalRegexp = regexp.MustCompile(`^\d+(\.\d+)*\.?\s+`)
+	headingNumberChineseRegexp = regexp.MustCompile(`^(第[零〇一二三四五六七八九十百]+章|\d+(\.\d+)*\.?)\s+`)
+)
+
+// HeadingNumbers 按文档顺序为 root 下的标题编号，返回标题节点到编号的映射。
+//
+// 级别小于 options.HeadingNumberStartLevel 的标题和 IAL 中带有 options.HeadingNumberExcludeIALAttr 属性的标题不编号，
+// 也不参与计数。
+func HeadingNumbers(root *ast.Node, options *Options) (ret map[*ast.Node]string) {
+	ret = map[*ast.Node]string{}
+	startLevel := options.HeadingNumberStartLevel
+	if 1 > startLevel {
+		startLevel = 1
+	}
+
+	counters := make([]int, 6)
+	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeHeading != n.Type {
+			return ast.WalkContinue
+		}
+
+		if n.HeadingLevel < startLevel {
+			return ast.WalkSkipChildren
+		}
+		if "" != options.HeadingNumberExcludeIALAttr && "" != n.IALAttr(options.HeadingNumberExcludeIALAttr) {
+			return ast.WalkSkipChildren
+		}
+
+		depth := n.HeadingLevel - startLevel
+		counters[depth]++
+		for i := depth + 1; i < len(counters); i++ {
+			counters[i] = 0
+		}
+		ret[n] = FormatHeadingNumber(counters[:depth+1], options.HeadingNumberStyle)
+		return ast.WalkSkipChildren
+	})
+	return
+}
+
+// FormatHeadingNumber 使用样式 style 格式化层级编号 numbers，比如 [1 2] 格式化为 1.2。
+func FormatHeadingNumber(numbers []int, style string) string {
+	if HeadingNumberStyleChinese == style && 1 == len(numbers) {
+		return "第" + chineseNumber(numbers[0]) + "章"
+	}
+
+	buf := &strings.Builder{}
+	for i, num := range numbers {
+		if 0 < i {
+			buf.WriteByte('.')
+		}
+		buf.WriteString(strconv.Itoa(num))
+	}
+	return buf.String()
+}
+
+var chineseDigits = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+
+// chineseNumber 将 0~999 转换为中文数字，比如 12 转换为十二，105 转换为一百零五，超出范围时使用阿拉伯数字。
+func chineseNumber(n int) string {
+	if 0 > n || 999 < n {
+		return strconv.Itoa(n)
+	}
+	if 10 > n {
+		return chineseDigits[n]
+	}
+	if 100 > n {
+		ret := "十"
+		if 1 < n/10 {
+			ret = chineseDigits[n/10] + ret
+		}
+		if 0 < n%10 {
+			ret += chineseDigits[n%10]
+		}
+		return ret
+	}
+
+	ret := chineseDigits[n/100] + "百"
+	rest := n % 100
+	if 0 == rest {
+		return ret
+	}
+	if 10 > rest {
+		return ret + "零" + chineseDigits[rest]
+	}
+	if 20 > rest {
+		// 一百一十二，十位的一不能省略
+		return ret + "一" + chineseNumber(rest)
+	}
+	return ret + chineseNumber(rest)
+}
+
+// headingNumber 返回标题 heading 的编号，没有打开标题编号或者标题不需要编号时返回空字符串。
+func (r *BaseRenderer) headingNumber(heading *ast.Node) string {
+	if !r.Options.HeadingNumber {
+		return ""
+	}
+	if nil == r.headingNumbers {
+		r.headingNumbers = HeadingNumbers(r.Tree.Root, r.Options)
+	}
+	return r.headingNumbers[heading]
+}
+
+// trimHeadingNumber 去掉标题 heading 的文本 tokens 开头已有的编号，用于刷新编号。
+//
+// 开头符合编号样式的数字（比如 1.2、第三章）都视为之前写入的编号，包括后来被排除编号的标题，所以打开标题编号后
+// 参与编号的级别的标题不能以这样的数字开头。
+func (r *BaseRenderer) trimHeadingNumber(heading *ast.Node, tokens []byte) []byte {
+	if !r.Options.HeadingNumber || heading.HeadingLevel < r.Options.HeadingNumberStartLevel {
+		return tokens
+	}
+
+	re := headingNumberDecimalRegexp
+	if HeadingNumberStyleChinese == r.Options.HeadingNumberStyle {
+		re = headingNumberChineseRegexp
+	}
+	if loc := re.FindIndex(tokens); nil != loc {
+		return tokens[loc[1]:]
+	}
+	return tokens
+}
+
+// headingNumberText 判断文本节点 text 是否位于标题开头，即已有编号所在的位置。
+func headingNumberText(text *ast.Node) bool {
+	return nil != text.Parent && ast.NodeHeading == text.Parent.Type && (nil == text.Previous || ast.NodeHeadingC8hMarker == text.Previous.Type)
+}
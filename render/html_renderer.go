@@ -115,6 +115,7 @@ func NewHtmlRenderer(tree *parse.Tree, options *Options) *HtmlRenderer {
 	ret.RendererFuncs[ast.NodeBlockRefID] = ret.renderBlockRefID
 	ret.RendererFuncs[ast.NodeBlockRefSpace] = ret.renderBlockRefSpace
 	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderBlockRefText
+	ret.RendererFuncs[ast.NodeBlockRefTextTplRenderResult] = ret.renderBlockRefTextTplRenderResult
 	ret.RendererFuncs[ast.NodeMark] = ret.renderMark
 	ret.RendererFuncs[ast.NodeMark1OpenMarker] = ret.renderMark1OpenMarker
 	ret.RendererFuncs[ast.NodeMark1CloseMarker] = ret.renderMark1CloseMarker
@@ -134,6 +135,7 @@ func NewHtmlRenderer(tree *parse.Tree, options *Options) *HtmlRenderer {
 	ret.RendererFuncs[ast.NodeBlockEmbedID] = ret.renderBlockEmbedID
 	ret.RendererFuncs[ast.NodeBlockEmbedSpace] = ret.renderBlockEmbedSpace
 	ret.RendererFuncs[ast.NodeBlockEmbedText] = ret.renderBlockEmbedText
+	ret.RendererFuncs[ast.NodeBlockEmbedTextTplRenderResult] = ret.renderBlockEmbedTextTplRenderResult
 	ret.RendererFuncs[ast.NodeTag] = ret.renderTag
 	ret.RendererFuncs[ast.NodeTagOpenMarker] = ret.renderTagOpenMarker
 	ret.RendererFuncs[ast.NodeTagCloseMarker] = ret.renderTagCloseMarker
@@ -324,6 +326,13 @@ func (r *HtmlRenderer) renderSubCloseMarker(node *ast.Node, entering bool) ast.W
 }
 
 func (r *HtmlRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.WalkStatus {
+	if nil != r.QueryExecutor {
+		if entering {
+			r.renderExecutedBlockQueryEmbed(node)
+		}
+		return ast.WalkSkipChildren
+	}
+
 	if entering {
 		r.Newline()
 		r.Tag("div", nil, false)
@@ -334,6 +343,28 @@ func (r *HtmlRenderer) renderBlockQueryEmbed(node *ast.Node, entering bool) ast.
 	return ast.WalkContinue
 }
 
+func (r *HtmlRenderer) renderExecutedBlockQueryEmbed(node *ast.Node) {
+	content, cycle, err := r.renderBlockQueryEmbedContent(node)
+	attrs := [][]string{{"data-type", "block-query-embed"}}
+	if script := node.ChildByType(ast.NodeBlockQueryEmbedScript); nil != script {
+		attrs = append(attrs, []string{"data-script", util.BytesToStr(html.EscapeHTML(script.Tokens))})
+	}
+	if cycle {
+		attrs = append(attrs, []string{"data-cycle", "true"})
+	}
+	if nil != err {
+		attrs = append(attrs, []string{"data-error", html.EscapeString(err.Error())})
+	}
+	r.Newline()
+	r.Tag("div", attrs, false)
+	if 0 < len(content) {
+		r.Newline()
+		r.Write(content)
+	}
+	r.Tag("/div", nil, false)
+	r.Newline()
+}
+
 func (r *HtmlRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
 		r.WriteByte(lex.ItemDoublequote)
@@ -344,6 +375,13 @@ func (r *HtmlRenderer) renderBlockQueryEmbedScript(node *ast.Node, entering bool
 }
 
 func (r *HtmlRenderer) renderBlockEmbed(node *ast.Node, entering bool) ast.WalkStatus {
+	if nil != r.BlockResolver {
+		if entering {
+			r.renderResolvedBlockEmbed(node)
+		}
+		return ast.WalkSkipChildren
+	}
+
 	if entering {
 		r.Newline()
 		r.handleKramdownBlockIAL(node)
@@ -372,7 +410,62 @@ func (r *HtmlRenderer) renderBlockEmbedText(node *ast.Node, entering bool) ast.W
 	return ast.WalkContinue
 }
 
+func (r *HtmlRenderer) renderResolvedBlockEmbed(node *ast.Node) {
+	id := node.ChildByType(ast.NodeBlockEmbedID).TokensStr()
+	content, state := r.renderBlockEmbedContent(id)
+	r.Newline()
+	r.handleKramdownBlockIAL(node)
+	attrs := [][]string{{"data-type", "block-embed"}, {"data-id", util.BytesToStr(html.EscapeHTML([]byte(id)))}}
+	attrs = append(attrs, node.KramdownIAL...)
+	switch state {
+	case blockEmbedDangling:
+		attrs = append(attrs, []string{"data-dangling", "true"})
+	case blockEmbedCycle:
+		attrs = append(attrs, []string{"data-cycle", "true"})
+	}
+	r.Tag("div", attrs, false)
+	if blockEmbedResolved == state {
+		r.Newline()
+		r.Write(content)
+	} else if text := node.ChildByType(ast.NodeBlockEmbedText); nil != text && 0 < len(text.Tokens) {
+		r.Write(html.EscapeHTML(text.Tokens))
+	}
+	r.Tag("/div", nil, false)
+	r.Newline()
+}
+
+func (r *HtmlRenderer) renderBlockEmbedTextTplRenderResult(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
 func (r *HtmlRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if nil == r.BlockResolver {
+		return ast.WalkContinue
+	}
+
+	if entering {
+		id := node.ChildByType(ast.NodeBlockRefID).TokensStr()
+		attrs := [][]string{{"data-type", "block-ref"}, {"data-id", util.BytesToStr(html.EscapeHTML([]byte(id)))}}
+		anchor, resolved := r.resolveBlockRef(node)
+		if !resolved {
+			attrs = append(attrs, []string{"data-dangling", "true"})
+		}
+		r.Tag("span", attrs, false)
+		if "" != anchor {
+			// 使用被引用内容块的文本作为锚文本
+			r.Write(html.EscapeHTML([]byte(anchor)))
+			return ast.WalkSkipChildren
+		}
+	} else {
+		r.Tag("/span", nil, false)
+	}
+	return ast.WalkContinue
+}
+
+func (r *HtmlRenderer) renderBlockRefTextTplRenderResult(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Write(html.EscapeHTML(node.Tokens))
+	}
 	return ast.WalkContinue
 }
 
@@ -385,6 +478,14 @@ func (r *HtmlRenderer) renderBlockRefSpace(node *ast.Node, entering bool) ast.Wa
 }
 
 func (r *HtmlRenderer) renderBlockRefText(node *ast.Node, entering bool) ast.WalkStatus {
+	if nil != r.BlockResolver {
+		if nil != node.Next && ast.NodeBlockRefTextTplRenderResult == node.Next.Type {
+			// 使用被引用内容块的文本作为锚文本
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	}
+
 	if entering {
 		r.WriteByte(lex.ItemDoublequote)
 	} else {
@@ -887,9 +988,16 @@ func (r *HtmlRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus
 			tokens = node.Tokens
 		}
 
+		if r.Options.ChinesePunct {
+			tokens = FixChinesePunct(tokens)
+		}
 		if r.Options.FixTermTypo {
 			tokens = r.FixTermTypo(tokens)
 		}
+		if headingNumberText(node) {
+			// 格式化时写入的编号会被重新生成
+			tokens = r.trimHeadingNumber(node.Parent, tokens)
+		}
 		r.Write(html.EscapeHTML(tokens))
 	}
 	return ast.WalkContinue
@@ -1038,7 +1146,7 @@ func (r *HtmlRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStat
 		r.Newline()
 		level := headingLevel[node.HeadingLevel : node.HeadingLevel+1]
 		r.WriteString("<h" + level)
-		id := HeadingID(node)
+		id := r.headingID(node)
 		if r.Options.ToC || r.Options.HeadingID || r.Options.KramdownBlockIAL {
 			r.WriteString(" id=\"" + id + "\"")
 			if r.Options.KramdownBlockIAL {
@@ -1054,9 +1162,12 @@ func (r *HtmlRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStat
 			}
 		}
 		r.WriteString(">")
+		if number := r.headingNumber(node); "" != number {
+			r.WriteString(number + " ")
+		}
 	} else {
 		if r.Options.HeadingAnchor {
-			id := HeadingID(node)
+			id := r.headingID(node)
 			r.Tag("a", [][]string{{"id", "vditorAnchor-" + id}, {"class", "vditor-anchor"}, {"href", "#" + id}}, false)
 			r.WriteString(`<svg viewBox="0 0 16 16" version="1.1" width="16" height="16"><path fill-rule="evenodd" d="M4 9h1v1H4c-1.5 0-3-1.69-3-3.5S2.55 3 4 3h4c1.45 0 3 1.69 3 3.5 0 1.41-.91 2.72-2 3.25V8.59c.58-.45 1-1.27 1-2.09C10 5.22 8.98 4 8 4H4c-.98 0-2 1.22-2 2.5S3 9 4 9zm9-3h-1v1h1c1 0 2 1.22 2 2.5S13.98 12 13 12H9c-.98 0-2-1.22-2-2.5 0-.83.42-1.64 1-2.09V6.25c-1.09.53-2 1.84-2 3.25C6 11.31 7.55 13 9 13h4c1.45 0 3-1.69 3-3.5S14.5 6 13 6z"></path></svg>`)
 			r.Tag("/a", nil, false)
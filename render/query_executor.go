@@ -0,0 +1,49 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/util"
+)
+
+// QueryExecutor 描述了内容块查询执行器，用于在渲染时执行内容块查询嵌入 !{{ script }} 中的脚本。
+type QueryExecutor interface {
+	// ExecuteQuery 执行查询脚本 script，返回匹配的内容块节点。
+	ExecuteQuery(script string) (blocks []*ast.Node, err error)
+}
+
+// renderBlockQueryEmbedContent 执行内容块查询嵌入 node 的脚本，返回查询结果渲染后的 HTML。
+func (r *BaseRenderer) renderBlockQueryEmbedContent(node *ast.Node) (content []byte, cycle bool, err error) {
+	script := node.ChildByType(ast.NodeBlockQueryEmbedScript)
+	if nil == script {
+		return
+	}
+	tokens := bytes.ReplaceAll(script.Tokens, util.CaretTokens, nil)
+	embedKey := "{{" + string(tokens) + "}}"
+	if r.blockEmbedInStack(embedKey) {
+		cycle = true
+		return
+	}
+
+	blocks, err := r.QueryExecutor.ExecuteQuery(string(tokens))
+	if nil != err {
+		return
+	}
+	buf := &bytes.Buffer{}
+	for _, block := range blocks {
+		buf.Write(r.renderEmbeddedHTML(block, embedKey))
+	}
+	content = buf.Bytes()
+	return
+}
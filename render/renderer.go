@@ -12,13 +12,12 @@ package render
 
 import (
 	"bytes"
-	"github.com/sunlightcs/lute/html"
 	"strconv"
 	"strings"
-	"unicode"
 	"unicode/utf8"
 
 	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/html"
 	"github.com/sunlightcs/lute/lex"
 	"github.com/sunlightcs/lute/parse"
 	"github.com/sunlightcs/lute/util"
@@ -76,6 +75,10 @@ type Options struct {
 	// https://github.com/sparanoid/chinese-copywriting-guidelines
 	// 注意：开启术语修正的话会默认在中西文之间插入空格。
 	FixTermTypo bool
+	// ChinesePunct 设置是否对普通文本中的中文标点进行修正，比如中文之间的半角标点转换为全角、全角数字和字母转换为半角、去掉重复的标点。
+	// 仅在 HTML 渲染器 HtmlRenderer 和格式化渲染器 FormatRenderer 中支持。
+	// https://github.com/sparanoid/chinese-copywriting-guidelines
+	ChinesePunct bool
 	// ToC 设置是否打开“目录”支持。
 	ToC bool
 	// HeadingID 设置是否打开“自定义标题 ID”支持。
@@ -95,6 +98,11 @@ type Options struct {
 	VditorHTMLBlockPreview bool
 	// Terms 将传入的 terms 合并覆盖到已有的 Terms 字典。
 	Terms map[string]string
+	// TermDicts 设置额外加载的术语字典（比如按领域划分的字典包），后加载的字典优先，这些字典都优先于 Terms。
+	TermDicts []*TermDict
+	// termsDict 是由 Terms 构建的字典树缓存，termsSnapshot 是构建时 Terms 的内容，Terms 内容变化后重新构建
+	termsDict     *TermDict
+	termsSnapshot map[string]string
 	// LinkBase 设置链接、图片的基础路径。如果用户在链接或者图片地址中使用相对路径（没有协议前缀且不以 / 开头）并且 LinkBase 不为空则会用该值作为前缀。
 	// 比如 LinkBase 设置为 http://domain.com/，对于 ![foo](bar.png) 则渲染为 <img src="http://domain.com/bar.png" alt="foo" />
 	LinkBase string
@@ -102,6 +110,35 @@ type Options struct {
 	// 比如 LinkPrefix 设置为 http://domain.com，对于使用绝对路径的 ![foo](/local/path/bar.png) 则渲染为 <img src="http://domain.com/local/path/bar.png" alt="foo" />；
 	// 在 LinkBase 和 LinkPrefix 同时设置的情况下，会先处理 LinkBase 逻辑，最后再在 LinkBase 处理结果上加上 LinkPrefix。
 	LinkPrefix string
+	// BlockEmbedMaxDepth 设置内容块嵌入的最大展开深度，仅在设置了内容块解析器 BlockResolver 时有效，默认为 8。
+	BlockEmbedMaxDepth int
+	// ToCMinLevel 设置目录中包含的最小标题级别，默认为 1。
+	ToCMinLevel int
+	// ToCMaxLevel 设置目录中包含的最大标题级别，默认为 6。
+	ToCMaxLevel int
+	// ToCOrdered 设置目录是否使用有序列表 <ol> 渲染，默认使用无序列表 <ul>。
+	ToCOrdered bool
+	// ToCTitle 设置目录标题，为空时不渲染目录标题。
+	ToCTitle string
+	// ToCExcludeIALAttr 设置目录排除属性名，标题的 kramdown 内联属性列表中该属性值不为空时标题不出现在目录中，默认为 "toc-exclude"。
+	// 比如 {: toc-exclude="true"}，需要打开 kramdown 块级内联属性列表支持。
+	ToCExcludeIALAttr string
+	// ToCNumbered 设置是否对目录条目按层级编号，比如 1、1.1、1.2。
+	ToCNumbered bool
+	// HeadingNumber 设置是否对标题按层级自动编号。HTML 渲染时在标题和目录中输出编号，格式化时将编号写入（或者刷新）到标题文本开头，标题文本开头已有的编号会被替换。
+	HeadingNumber bool
+	// HeadingNumberStartLevel 设置从哪一级标题开始编号，更低级别的标题不编号，默认为 1。
+	HeadingNumberStartLevel int
+	// HeadingNumberStyle 设置标题编号样式，支持 "decimal"（1、1.1）和 "chinese"（第一章、1.1），默认为 "decimal"。
+	HeadingNumberStyle string
+	// HeadingNumberExcludeIALAttr 设置标题编号排除属性名，标题的 kramdown 内联属性列表中该属性值不为空时标题不编号，默认为 "number-exclude"。
+	HeadingNumberExcludeIALAttr string
+	// FormatWrapWidth 设置格式化时段落（包括列表项和引述中的段落）的折行宽度，中日韩字符宽度计为 2，默认为 0 即保持原有换行。
+	FormatWrapWidth int
+	// FormatUnwrap 设置格式化时是否将段落中的软换行合并为一行，打开后 FormatWrapWidth 不生效。
+	FormatUnwrap bool
+	// FormatStyle 设置格式化输出的样式，比如列表标记符、强调标记符和标题样式。
+	FormatStyle *FormatStyle
 }
 
 func NewOptions() *Options {
@@ -119,6 +156,7 @@ func NewOptions() *Options {
 		KramdownBlockIAL:               false,
 		ChineseParagraphBeginningSpace: false,
 		FixTermTypo:                    false,
+		ChinesePunct:                   false,
 		ToC:                            false,
 		HeadingID:                      false,
 		KramdownIALIDRenderName:        "id",
@@ -128,6 +166,15 @@ func NewOptions() *Options {
 		VditorHTMLBlockPreview:         true,
 		LinkBase:                       "",
 		LinkPrefix:                     "",
+		Terms:                          NewTerms(),
+		BlockEmbedMaxDepth:             8,
+		ToCMinLevel:                    1,
+		ToCMaxLevel:                    6,
+		ToCExcludeIALAttr:              "toc-exclude",
+		HeadingNumberStartLevel:        1,
+		HeadingNumberStyle:             HeadingNumberStyleDecimal,
+		HeadingNumberExcludeIALAttr:    "number-exclude",
+		FormatStyle:                    NewFormatStyle(),
 	}
 }
 
@@ -143,11 +190,19 @@ type BaseRenderer struct {
 	DisableTags         int                              // 标签嵌套计数器，用于判断不可能出现标签嵌套的情况，比如语法树允许图片节点包含链接节点，但是 HTML <img> 不能包含 <a>
 	FootnotesDefs       []*ast.Node                      // 脚注定义集
 	RenderingFootnotes  bool                             // 是否正在渲染脚注定义
+	BlockResolver       BlockResolver                    // 内容块解析器，用于生成内容块引用锚文本和展开内容块嵌入
+	QueryExecutor       QueryExecutor                    // 内容块查询执行器，用于展开内容块查询嵌入
+	resolvedBlocks      map[string]*ast.Node             // 已解析的内容块缓存
+	blockEmbedStack     []string                         // 正在展开的内容块嵌入 ID 栈，用于检测循环嵌入
+	headingNumbers      map[*ast.Node]string             // 标题编号缓存
+	termDicts           []*TermDict                      // 修正术语拼写时使用的字典列表
 }
 
 // NewBaseRenderer 构造一个 BaseRenderer。
 func NewBaseRenderer(tree *parse.Tree, options *Options) *BaseRenderer {
-	options.Terms = NewTerms()
+	if nil == options.Terms {
+		options.Terms = NewTerms()
+	}
 	ret := &BaseRenderer{RendererFuncs: map[ast.NodeType]RendererFunc{}, ExtRendererFuncs: map[ast.NodeType]ExtRendererFunc{}, Options: options, Tree: tree}
 	ret.Writer = &bytes.Buffer{}
 	ret.Writer.Grow(4096)
@@ -160,28 +215,31 @@ func (r *BaseRenderer) Render() (output []byte) {
 	r.Writer = &bytes.Buffer{}
 	r.Writer.Grow(4096)
 
-	ast.Walk(r.Tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
-		extRender := r.ExtRendererFuncs[n.Type]
-		if nil != extRender {
-			output, status := extRender(n, entering)
-			r.WriteString(output)
-			return status
-		}
-
-		render := r.RendererFuncs[n.Type]
-		if nil == render {
-			if nil != r.DefaultRendererFunc {
-				return r.DefaultRendererFunc(n, entering)
-			}
-			return r.renderDefault(n, entering)
-		}
-		return render(n, entering)
-	})
+	ast.Walk(r.Tree.Root, r.renderNode)
 
 	output = r.Writer.Bytes()
 	return
 }
 
+// renderNode 使用节点 n 对应的渲染器渲染节点。
+func (r *BaseRenderer) renderNode(n *ast.Node, entering bool) ast.WalkStatus {
+	extRender := r.ExtRendererFuncs[n.Type]
+	if nil != extRender {
+		output, status := extRender(n, entering)
+		r.WriteString(output)
+		return status
+	}
+
+	render := r.RendererFuncs[n.Type]
+	if nil == render {
+		if nil != r.DefaultRendererFunc {
+			return r.DefaultRendererFunc(n, entering)
+		}
+		return r.renderDefault(n, entering)
+	}
+	return render(n, entering)
+}
+
 func (r *BaseRenderer) renderDefault(n *ast.Node, entering bool) ast.WalkStatus {
 	r.WriteString("not found render function for node [type=" + n.Type.String() + ", Tokens=" + util.BytesToStr(n.Tokens) + "]")
 	return ast.WalkContinue
@@ -295,53 +353,14 @@ func SubStr(str string, length int) (ret string) {
 	return
 }
 
+// HeadingID 返回标题 heading 的 ID，实现见 parse.HeadingID。
 func HeadingID(heading *ast.Node) (ret string) {
-	if 0 == len(util.StrToBytes(heading.HeadingNormalizedID)) {
-		headingID0(heading)
-	}
-	return heading.HeadingNormalizedID
-}
-
-func headingID0(heading *ast.Node) {
-	var root *ast.Node
-	for root = heading.Parent; ast.NodeDocument != root.Type; root = root.Parent {
-	}
-
-	idOccurs := map[string]int{}
-	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
-		if entering {
-			if ast.NodeHeading == n.Type {
-				id := normalizeHeadingID(n)
-				for ; 0 < idOccurs[id]; id += "-" {
-				}
-				n.HeadingNormalizedID = id
-				idOccurs[id] = 1
-			}
-		}
-		return ast.WalkContinue
-	})
+	return parse.HeadingID(heading)
 }
 
-func normalizeHeadingID(heading *ast.Node) (ret string) {
-	headingID := heading.ChildByType(ast.NodeHeadingID)
-	var id string
-	if nil != headingID {
-		id = util.BytesToStr(headingID.Tokens)
-	}
-	if "" == id {
-		id = heading.Text()
-	}
-
-	id = strings.TrimLeft(id, "#")
-	id = strings.ReplaceAll(id, util.Caret, "")
-	for _, r := range id {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			ret += string(r)
-		} else {
-			ret += "-"
-		}
-	}
-	return
+// headingID 按照解析选项设置的生成方式返回标题 heading 的 ID，实现见 parse.Tree.HeadingID。
+func (r *BaseRenderer) headingID(heading *ast.Node) string {
+	return r.Tree.HeadingID(heading)
 }
 
 type Heading struct {
@@ -350,22 +369,15 @@ type Heading struct {
 	ID       string     `json:"id"`
 	Content  string     `json:"content"`
 	Level    int        `json:"level"`
+	Number   string     `json:"number,omitempty"`
 	Children []*Heading `json:"children"`
 	parent   *Heading
 }
 
 func (r *BaseRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
 	if entering {
-		headings := r.headings()
-		length := len(headings)
 		r.WriteString("<div class=\"vditor-toc\" data-block=\"0\" data-type=\"toc-block\" contenteditable=\"false\">")
-		if 0 < length {
-			r.WriteString("<ul>")
-			for _, child := range headings {
-				r.renderToC0(child)
-			}
-			r.WriteString("</ul>")
-		} else {
+		if !r.renderToCContent() {
 			r.WriteString("[toc]<br>")
 		}
 		r.WriteString("</div>")
@@ -373,19 +385,69 @@ func (r *BaseRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
 	return ast.WalkContinue
 }
 
-func (r *BaseRenderer) renderToC0(heading *Heading) {
-	r.WriteString("<li>")
-	r.Tag("a", [][]string{{"href", heading.ID}}, false)
-	r.WriteString("#" + heading.Content)
-	r.Tag("/a", nil, false)
-	if 0 < len(heading.Children) {
-		r.WriteString("<ul>")
-		for _, child := range heading.Children {
-			r.renderToC0(child)
+// ToC 将语法树 tree 的目录渲染为 HTML，包括目录标题和目录列表，没有标题时返回空字符串。
+func ToC(tree *parse.Tree, options *Options) string {
+	renderer := NewHtmlRenderer(tree, options)
+	renderer.Writer = &bytes.Buffer{}
+	renderer.renderToCContent()
+	return renderer.Writer.String()
+}
+
+// renderToCContent 渲染目录标题和目录列表，没有需要出现在目录中的标题时不输出并返回 false。
+func (r *BaseRenderer) renderToCContent() bool {
+	headings := r.headings()
+	if 1 > len(headings) {
+		return false
+	}
+
+	if "" != r.Options.ToCTitle {
+		r.WriteString("<div class=\"vditor-toc__title\">")
+		r.Write(html.EscapeHTML([]byte(r.Options.ToCTitle)))
+		r.WriteString("</div>")
+	}
+	r.renderToC0(headings, "")
+	return true
+}
+
+// renderToC0 渲染目录列表，numberPrefix 为上级条目的编号前缀。
+func (r *BaseRenderer) renderToC0(headings []*Heading, numberPrefix string) {
+	listTag := "ul"
+	if r.Options.ToCOrdered {
+		listTag = "ol"
+	}
+
+	r.WriteString("<" + listTag + ">")
+	for i, heading := range headings {
+		number := numberPrefix + strconv.Itoa(i+1)
+		r.WriteString("<li>")
+		r.Tag("span", [][]string{{"data-target-id", heading.ID}}, false)
+		if r.Options.ToCNumbered {
+			r.WriteString(number + " ")
+		} else if "" != heading.Number {
+			r.WriteString(heading.Number + " ")
+		}
+		r.WriteString(heading.Content)
+		r.Tag("/span", nil, false)
+		if 0 < len(heading.Children) {
+			r.renderToC0(heading.Children, number+".")
 		}
-		r.WriteString("</ul>")
+		r.WriteString("</li>")
 	}
-	r.WriteString("</li>")
+	r.WriteString("</" + listTag + ">")
+}
+
+// inToC 判断标题 heading 是否需要出现在目录中。
+func (r *BaseRenderer) inToC(heading *ast.Node) bool {
+	if 0 < r.Options.ToCMinLevel && heading.HeadingLevel < r.Options.ToCMinLevel {
+		return false
+	}
+	if 0 < r.Options.ToCMaxLevel && heading.HeadingLevel > r.Options.ToCMaxLevel {
+		return false
+	}
+	if "" != r.Options.ToCExcludeIALAttr && "" != heading.IALAttr(r.Options.ToCExcludeIALAttr) {
+		return false
+	}
+	return true
 }
 
 func (r *BaseRenderer) Tag(name string, attrs [][]string, selfclosing bool) {
@@ -410,11 +472,11 @@ func (r *BaseRenderer) headings() (ret []*Heading) {
 	headings := r.Tree.Root.ChildrenByType(ast.NodeHeading)
 	var tip *Heading
 	for _, heading := range headings {
-		if r.Tree.Root != heading.Parent {
+		if r.Tree.Root != heading.Parent || !r.inToC(heading) {
 			continue
 		}
 
-		id := HeadingID(heading)
+		id := r.headingID(heading)
 		if r.Options.VditorWYSIWYG {
 			id = "wysiwyg-" + id
 		} else if r.Options.VditorIR {
@@ -434,8 +496,9 @@ func (r *BaseRenderer) headings() (ret []*Heading) {
 			URL:     r.Tree.URL,
 			Path:    r.Tree.Path,
 			ID:      id,
-			Content: headingText(heading),
+			Content: string(r.trimHeadingNumber(heading, []byte(headingText(heading)))),
 			Level:   heading.HeadingLevel,
+			Number:  r.headingNumber(heading),
 		}
 
 		if nil == tip {
@@ -646,48 +709,7 @@ func (r *BaseRenderer) NoHighlight(language string) bool {
 	return false
 }
 
+// RenderHeadingText 返回标题 n 的文本，实现见 parse.RenderHeadingText。
 func RenderHeadingText(n *ast.Node) (ret string) {
-	buf := &bytes.Buffer{}
-	ast.Walk(n, func(n *ast.Node, entering bool) ast.WalkStatus {
-		if !entering {
-			return ast.WalkContinue
-		}
-
-		switch n.Type {
-		case ast.NodeLinkText, ast.NodeBlockRefText, ast.NodeBlockEmbedText:
-			buf.Write(n.Tokens)
-		case ast.NodeInlineMathContent:
-			buf.WriteString("<span class=\"language-math\">")
-			buf.Write(html.EscapeHTML(n.Tokens))
-			buf.WriteString("</span>")
-		case ast.NodeCodeSpanContent:
-			buf.WriteString("<code>")
-			buf.Write(html.EscapeHTML(n.Tokens))
-			buf.WriteString("</code>")
-		case ast.NodeText:
-			if n.ParentIs(ast.NodeStrong) {
-				buf.WriteString("<strong>")
-				buf.Write(html.EscapeHTML(n.Tokens))
-				buf.WriteString("</strong>")
-			} else if n.ParentIs(ast.NodeEmphasis) {
-				buf.WriteString("<em>")
-				buf.Write(html.EscapeHTML(n.Tokens))
-				buf.WriteString("</em>")
-			} else {
-				if nil != n.Previous && ast.NodeInlineHTML == n.Previous.Type {
-					if !bytes.HasPrefix(n.Previous.Tokens, []byte("</")) {
-						buf.Write(n.Previous.Tokens)
-						buf.Write(html.EscapeHTML(n.Tokens))
-					} else {
-						buf.Write(n.Previous.Tokens)
-						buf.Write(html.EscapeHTML(n.Tokens))
-					}
-				} else {
-					buf.Write(html.EscapeHTML(n.Tokens))
-				}
-			}
-		}
-		return ast.WalkContinue
-	})
-	return buf.String()
+	return parse.RenderHeadingText(n)
 }
@@ -0,0 +1,262 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sunlightcs/lute/lex"
+)
+
+// TermDict 描述了术语字典。
+//
+// 术语保存在字典树中，匹配耗时只和文本长度以及术语长度有关，和字典大小无关。术语不区分大小写，
+// 可以包含空格（比如 Visual Studio Code），匹配时文本中连续的空白视为一个空格。
+type TermDict struct {
+	root *termNode
+	size int
+}
+
+// termNode 描述了字典树节点。
+type termNode struct {
+	children map[byte]*termNode
+	correct  string // 以该节点结尾的术语的正确写法，为空时表示没有以该节点结尾的术语
+}
+
+// TermCorrection 描述了一处术语拼写修正。
+type TermCorrection struct {
+	Offset int    // 在文本中的字节偏移
+	From   string // 原文本
+	To     string // 术语的正确写法
+}
+
+// NewTermDict 创建一个空的术语字典。
+func NewTermDict() *TermDict {
+	return &TermDict{root: &termNode{}}
+}
+
+// LoadTermDict 从文件 path 加载术语字典，文件格式见 TermDict.Load。
+func LoadTermDict(path string) (ret *TermDict, err error) {
+	file, err := os.Open(path)
+	if nil != err {
+		return
+	}
+	defer file.Close()
+
+	ret = NewTermDict()
+	if err = ret.Load(file); nil != err {
+		err = errors.New(path + ": " + err.Error())
+	}
+	return
+}
+
+// Load 从 reader 中加载术语，每行一个 term=Correct，忽略空行和 # 开头的注释行。
+func (dict *TermDict) Load(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if "" == text || '#' == text[0] {
+			continue
+		}
+		i := strings.IndexByte(text, '=')
+		if 1 > i || "" == strings.TrimSpace(text[:i]) || "" == strings.TrimSpace(text[i+1:]) {
+			return errors.New("invalid term [" + text + "] at line " + strconv.Itoa(line))
+		}
+		dict.Put(text[:i], strings.TrimSpace(text[i+1:]))
+	}
+	return scanner.Err()
+}
+
+// Put 添加术语 term，correct 为其正确写法。
+func (dict *TermDict) Put(term, correct string) {
+	key := normalizeTerm(term)
+	if "" == key || "" == correct {
+		return
+	}
+
+	node := dict.root
+	for i := 0; i < len(key); i++ {
+		if nil == node.children {
+			node.children = map[byte]*termNode{}
+		}
+		child := node.children[key[i]]
+		if nil == child {
+			child = &termNode{}
+			node.children[key[i]] = child
+		}
+		node = child
+	}
+	if "" == node.correct {
+		dict.size++
+	}
+	node.correct = correct
+}
+
+// PutAll 添加 terms 中的所有术语，键为术语，值为其正确写法。
+func (dict *TermDict) PutAll(terms map[string]string) {
+	for term, correct := range terms {
+		dict.Put(term, correct)
+	}
+}
+
+// Len 返回字典中的术语数量。
+func (dict *TermDict) Len() int {
+	return dict.size
+}
+
+// match 返回 tokens 中从 start 开始的最长术语的结束位置和正确写法，没有匹配时 end 为 -1。
+func (dict *TermDict) match(tokens []byte, start int) (end int, correct string) {
+	end = -1
+	node := dict.root
+	for i := start; i < len(tokens) && nil != node; {
+		token := tokens[i]
+		if lex.IsWhitespace(token) {
+			for i < len(tokens) && lex.IsWhitespace(tokens[i]) {
+				i++
+			}
+			token = lex.ItemSpace
+		} else {
+			token = toLowerASCII(token)
+			i++
+		}
+		if node = node.children[token]; nil != node && "" != node.correct && isTermEnd(tokens, i) {
+			end, correct = i, node.correct
+		}
+	}
+	return
+}
+
+// TermDicts 返回按照渲染选项 options 修正术语拼写时使用的字典列表：后加载的字典 TermDicts 在前，最后是由 Terms 构建的字典。
+func TermDicts(options *Options) (ret []*TermDict) {
+	for i := len(options.TermDicts) - 1; 0 <= i; i-- {
+		ret = append(ret, options.TermDicts[i])
+	}
+	if nil == options.termsDict || !sameTerms(options.Terms, options.termsSnapshot) {
+		if sameTerms(options.Terms, terms) {
+			// 没有修改过内置术语时共用同一个字典树
+			options.termsDict, options.termsSnapshot = builtinTermDict(), terms
+		} else {
+			options.termsDict, options.termsSnapshot = NewTermDict(), make(map[string]string, len(options.Terms))
+			for term, correct := range options.Terms {
+				options.termsDict.Put(term, correct)
+				options.termsSnapshot[term] = correct
+			}
+		}
+	}
+	return append(ret, options.termsDict)
+}
+
+var (
+	builtinTermDictOnce sync.Once
+	builtinTermDictVal  *TermDict
+)
+
+// builtinTermDict 返回由内置术语 terms 构建的字典树，第一次使用时构建，之后只读共享。
+func builtinTermDict() *TermDict {
+	builtinTermDictOnce.Do(func() {
+		builtinTermDictVal = NewTermDict()
+		builtinTermDictVal.PutAll(terms)
+	})
+	return builtinTermDictVal
+}
+
+// sameTerms 判断术语字典 a 和 b 的内容是否相同。
+func sameTerms(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for term, correct := range a {
+		if c, ok := b[term]; !ok || c != correct {
+			return false
+		}
+	}
+	return true
+}
+
+// TermCorrections 返回按照字典列表 dicts 修正 tokens 中的术语拼写需要做出的修改。
+//
+// 同一位置匹配到多个术语时使用最长的术语，长度相同时前面的字典优先。术语前后必须是空白、非 ASCII 字符或者文本边界，
+// 所以 github.com、test.html 这样的文本不会被修正。
+func TermCorrections(tokens []byte, dicts []*TermDict) (ret []*TermCorrection) {
+	for i := 0; i < len(tokens); i++ {
+		if !isTermStart(tokens, i) {
+			continue
+		}
+
+		end, correct := -1, ""
+		for _, dict := range dicts {
+			if e, c := dict.match(tokens, i); e > end {
+				end, correct = e, c
+			}
+		}
+		if 0 > end {
+			continue
+		}
+		if from := string(tokens[i:end]); from != correct {
+			ret = append(ret, &TermCorrection{Offset: i, From: from, To: correct})
+		}
+		i = end - 1
+	}
+	return
+}
+
+// FixTerms 按照字典列表 dicts 修正 tokens 中的术语拼写，规则见 TermCorrections。
+func FixTerms(tokens []byte, dicts []*TermDict) []byte {
+	corrections := TermCorrections(tokens, dicts)
+	if 1 > len(corrections) {
+		return tokens
+	}
+
+	ret := make([]byte, 0, len(tokens))
+	start := 0
+	for _, correction := range corrections {
+		ret = append(ret, tokens[start:correction.Offset]...)
+		ret = append(ret, correction.To...)
+		start = correction.Offset + len(correction.From)
+	}
+	return append(ret, tokens[start:]...)
+}
+
+// normalizeTerm 将术语 term 转换为字典树中的键：ASCII 字母转为小写，连续的空白合并为一个空格。
+func normalizeTerm(term string) string {
+	ret := []byte(strings.Join(strings.Fields(term), " "))
+	for i := range ret {
+		ret[i] = toLowerASCII(ret[i])
+	}
+	return string(ret)
+}
+
+func toLowerASCII(token byte) byte {
+	if 'A' <= token && 'Z' >= token {
+		return token + 'a' - 'A'
+	}
+	return token
+}
+
+// isTermStart 判断 tokens 中的位置 i 是否可以作为术语的开始。
+func isTermStart(tokens []byte, i int) bool {
+	if lex.IsWhitespace(tokens[i]) || !utf8.RuneStart(tokens[i]) {
+		return false
+	}
+	return 0 == i || lex.IsWhitespace(tokens[i-1]) || utf8.RuneSelf <= tokens[i-1]
+}
+
+// isTermEnd 判断 tokens 中的位置 i 是否可以作为术语的结束（不包含）。
+func isTermEnd(tokens []byte, i int) bool {
+	return len(tokens) == i || lex.IsWhitespace(tokens[i]) || utf8.RuneSelf <= tokens[i]
+}
@@ -10,70 +10,12 @@
 
 package render
 
-import (
-	"bytes"
-	"unicode/utf8"
-
-	"github.com/sunlightcs/lute/lex"
-	"github.com/sunlightcs/lute/util"
-)
-
 // FixTermTypo 修正 tokens 中出现的术语拼写问题。
 func (r *BaseRenderer) FixTermTypo(tokens []byte) []byte {
-	return r.fixTermTypo0(tokens)
-}
-
-func (r *BaseRenderer) fixTermTypo0(tokens []byte) []byte {
-	length := len(tokens)
-	var token byte
-	var i, j, k, l int
-	var before, after byte
-	var originalTerm []byte
-	for ; i < length; i++ {
-		token = tokens[i]
-		if isNotTerm(token) {
-			continue
-		}
-		if 1 <= i {
-			before = tokens[i-1]
-			if !isNotTerm(before) {
-				// 前一个字节必须是非术语，否则无法分隔
-				continue
-			}
-		}
-		if lex.IsASCIIPunct(before) {
-			// 比如术语前面如果是 . 则不进行修正，因为可能是链接
-			// 比如 test.html 虽然不能识别为自动链接，但是也不能进行修正
-			continue
-		}
-
-		for j = i; j < length; j++ {
-			after = tokens[j]
-			if isNotTerm(after) || lex.ItemDot == after {
-				break
-			}
-		}
-		if lex.IsASCIIPunct(after) {
-			// 比如术语后面如果是 . 则不进行修正，因为可能是链接
-			// 比如 github.com 虽然不能识别为自动链接，但是也不能进行修正
-			continue
-		}
-
-		originalTerm = bytes.ToLower(tokens[i:j])
-		if to, ok := r.Options.Terms[util.BytesToStr(originalTerm)]; ok {
-			l = 0
-			for k = i; k < j; k++ {
-				tokens[k] = to[l]
-				l++
-			}
-		}
+	if nil == r.termDicts {
+		r.termDicts = TermDicts(r.Options)
 	}
-
-	return tokens
-}
-
-func isNotTerm(token byte) bool {
-	return token >= utf8.RuneSelf || lex.IsWhitespace(token) || lex.IsASCIIPunct(token)
+	return FixTerms(tokens, r.termDicts)
 }
 
 func NewTerms() (ret map[string]string) {
@@ -454,6 +454,20 @@ func (r *VditorIRBlockRenderer) renderBlockEmbed(node *ast.Node, entering bool)
 		r.renderDivNode(node)
 	} else {
 		id := node.ChildByType(ast.NodeBlockEmbedID)
+		if nil != r.BlockResolver {
+			content, state := r.renderBlockEmbedContent(id.TokensStr())
+			attrs := [][]string{{"data-block-def-id", id.TokensStr()}, {"data-render", "2"}, {"data-type", "block-render"}}
+			switch state {
+			case blockEmbedDangling:
+				attrs = append(attrs, []string{"data-dangling", "true"})
+			case blockEmbedCycle:
+				attrs = append(attrs, []string{"data-cycle", "true"})
+			}
+			r.Tag("div", attrs, false)
+			r.Write(content)
+			r.WriteString("</div></div>")
+			return ast.WalkContinue
+		}
 		r.WriteString("<div data-block-def-id=\"" + string(id.Tokens) + "\" data-render=\"2\" data-type=\"block-render\"></div>")
 		r.WriteString("</div>")
 	}
@@ -553,6 +567,11 @@ func (r *VditorIRBlockRenderer) renderBlockRefText(node *ast.Node, entering bool
 		r.Tag("/span", nil, false)
 		if nil == node.Next || ast.NodeBlockRefTextTplRenderResult != node.Next.Type {
 			r.Tag("span", [][]string{{"data-type", "ref-text-tpl-render-result"}, {"class", "vditor-ir__blockref"}}, false)
+			if nil != r.BlockResolver {
+				// 使用被引用内容块的文本作为锚文本
+				anchor, _ := r.resolveBlockRef(node.Parent)
+				r.Write(html.EscapeHTML([]byte(anchor)))
+			}
 			r.Tag("/span", nil, false)
 		}
 	}
@@ -1709,7 +1728,7 @@ func (r *VditorIRBlockRenderer) renderHeading(node *ast.Node, entering bool) ast
 		}
 
 		if r.Options.HeadingAnchor {
-			id := HeadingID(node)
+			id := r.headingID(node)
 			r.Tag("a", [][]string{{"id", "vditorAnchor-" + id}, {"class", "vditor-anchor"}, {"href", "#" + id}}, false)
 			r.WriteString(`<svg viewBox="0 0 16 16" version="1.1" width="16" height="16"><path fill-rule="evenodd" d="M4 9h1v1H4c-1.5 0-3-1.69-3-3.5S2.55 3 4 3h4c1.45 0 3 1.69 3 3.5 0 1.41-.91 2.72-2 3.25V8.59c.58-.45 1-1.27 1-2.09C10 5.22 8.98 4 8 4H4c-.98 0-2 1.22-2 2.5S3 9 4 9zm9-3h-1v1h1c1 0 2 1.22 2 2.5S13.98 12 13 12H9c-.98 0-2-1.22-2-2.5 0-.83.42-1.64 1-2.09V6.25c-1.09.53-2 1.84-2 3.25C6 11.31 7.55 13 9 13h4c1.45 0 3-1.69 3-3.5S14.5 6 13 6z"></path></svg>`)
 			r.Tag("/a", nil, false)
@@ -1906,6 +1925,9 @@ func (r *VditorIRBlockRenderer) renderSpanNode(node *ast.Node) {
 		}
 	case ast.NodeBlockRef:
 		attrs = append(attrs, []string{"data-type", "block-ref"})
+		if id := node.ChildByType(ast.NodeBlockRefID); nil != r.BlockResolver && nil != id && nil == r.resolveBlock(id.TokensStr()) {
+			attrs = append(attrs, []string{"data-dangling", "true"})
+		}
 	case ast.NodeImage:
 		attrs = append(attrs, []string{"data-type", "img"})
 	case ast.NodeCodeSpan:
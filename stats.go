@@ -0,0 +1,178 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+// 阅读速度，用于估算阅读时间。
+const (
+	CJKCharsPerMinute   = 300 // 每分钟阅读的中日韩字符数
+	LatinWordsPerMinute = 200 // 每分钟阅读的西文单词数
+)
+
+// Stats 描述了文档统计信息。
+//
+// 字数只统计正文文本，代码、数学公式、HTML、链接地址、图片、内联属性列表和各种标记符都不计入。
+type Stats struct {
+	CJKChars       int           `json:"cjkChars"`       // 中日韩字符数
+	LatinWords     int           `json:"latinWords"`     // 西文单词数，连续的字母或者数字算作一个单词
+	Words          int           `json:"words"`          // 总字数，即中日韩字符数加西文单词数
+	Paragraphs     int           `json:"paragraphs"`     // 段落数
+	Headings       int           `json:"headings"`       // 标题数
+	Images         int           `json:"images"`         // 图片数
+	Links          int           `json:"links"`          // 链接数，不包括链接引用定义
+	CodeBlocks     int           `json:"codeBlocks"`     // 代码块数
+	Tables         int           `json:"tables"`         // 表格数
+	ReadingMinutes int           `json:"readingMinutes"` // 估算的阅读时间（分钟），有正文时至少为 1
+	Blocks         []*BlockStats `json:"blocks"`         // 按叶子块分别统计的结果
+}
+
+// BlockStats 描述了叶子块（段落、标题、表格、代码块、数学公式块和 HTML 块）的统计信息。
+type BlockStats struct {
+	ID         string `json:"id,omitempty"` // 块 ID，取自内联属性列表
+	Type       string `json:"type"`         // 块类型，比如 NodeParagraph
+	CJKChars   int    `json:"cjkChars"`     // 中日韩字符数
+	LatinWords int    `json:"latinWords"`   // 西文单词数
+	Images     int    `json:"images"`       // 图片数
+	Links      int    `json:"links"`        // 链接数
+}
+
+// Stats 统计 markdown 的字数、各种块的数量并估算阅读时间。
+func (lute *Lute) Stats(markdown string) (ret *Stats, err error) {
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		return
+	}
+	ret = lute.TreeStats(tree)
+	return
+}
+
+// TreeStats 统计语法树 tree 的字数、各种块的数量并估算阅读时间。
+func (lute *Lute) TreeStats(tree *parse.Tree) (ret *Stats) {
+	ret = &Stats{Blocks: []*BlockStats{}}
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+
+		switch n.Type {
+		case ast.NodeParagraph:
+			ret.Paragraphs++
+		case ast.NodeHeading:
+			ret.Headings++
+		case ast.NodeCodeBlock:
+			ret.CodeBlocks++
+		case ast.NodeTable:
+			ret.Tables++
+		case ast.NodeMathBlock, ast.NodeHTMLBlock:
+		default:
+			return ast.WalkContinue
+		}
+
+		block := blockStats(n)
+		ret.Blocks = append(ret.Blocks, block)
+		ret.CJKChars += block.CJKChars
+		ret.LatinWords += block.LatinWords
+		ret.Images += block.Images
+		ret.Links += block.Links
+		return ast.WalkSkipChildren
+	})
+
+	ret.Words = ret.CJKChars + ret.LatinWords
+	if 0 < ret.Words {
+		minutes := float64(ret.CJKChars)/CJKCharsPerMinute + float64(ret.LatinWords)/LatinWordsPerMinute
+		ret.ReadingMinutes = int(minutes)
+		if float64(ret.ReadingMinutes) < minutes {
+			ret.ReadingMinutes++
+		}
+	}
+	return
+}
+
+// blockStats 统计叶子块 block 的文本。
+func blockStats(block *ast.Node) (ret *BlockStats) {
+	ret = &BlockStats{ID: block.IALAttr("id"), Type: block.Type.String()}
+	if ast.NodeCodeBlock == block.Type || ast.NodeMathBlock == block.Type || ast.NodeHTMLBlock == block.Type {
+		return
+	}
+
+	text := &strings.Builder{}
+	ast.Walk(block, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			if ast.NodeTableCell == n.Type {
+				text.WriteByte(' ')
+			}
+			return ast.WalkContinue
+		}
+
+		switch n.Type {
+		case ast.NodeText, ast.NodeBackslashContent:
+			text.Write(n.Tokens)
+		case ast.NodeLinkText:
+			if ast.NodeLink == n.Parent.Type {
+				text.Write(n.Tokens)
+			}
+		case ast.NodeSoftBreak, ast.NodeHardBreak:
+			text.WriteByte(' ')
+		case ast.NodeCodeSpan, ast.NodeInlineMath, ast.NodeInlineHTML:
+			text.WriteByte(' ')
+			return ast.WalkSkipChildren
+		case ast.NodeImage:
+			ret.Images++
+			return ast.WalkSkipChildren
+		case ast.NodeLink:
+			ret.Links++
+			if 2 == n.LinkType {
+				// 自动链接的文本就是链接地址
+				return ast.WalkSkipChildren
+			}
+		}
+		return ast.WalkContinue
+	})
+	ret.CJKChars, ret.LatinWords = countWords(text.String())
+	return
+}
+
+// countWords 分别统计 text 中的中日韩字符数和西文单词数。
+func countWords(text string) (cjkChars, latinWords int) {
+	inWord := false
+	for _, r := range text {
+		if isCJK(r) {
+			cjkChars++
+			inWord = false
+			continue
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if !inWord {
+				latinWords++
+				inWord = true
+			}
+			continue
+		}
+		if inWord && ('\'' == r || '’' == r || '-' == r || '_' == r) {
+			// 单词中间的撇号、连字符和下划线不断词，比如 don't、well-known
+			continue
+		}
+		inWord = false
+	}
+	return
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
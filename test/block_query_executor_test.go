@@ -0,0 +1,49 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/query"
+)
+
+var blockQueryExecutorTests = []parseTest{
+
+	{"6", "!{{ SELECT * FROM blocks LIMIT １０ }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks LIMIT １０\" data-error=\"query syntax error at position 27: unexpected [１]\"></div>\n"},
+	{"5", "!{{ SELECT * FROM blocks WHERE }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE\" data-error=\"query syntax error at position 26: expected field name\"></div>\n"},
+	{"4", "!{{ SELECT * FROM blocks WHERE type = 'h' AND NOT content LIKE '%bar%' }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE type = 'h' AND NOT content LIKE '%bar%'\">\n<h1>foo</h1>\n</div>\n"},
+	{"3", "!{{ SELECT * FROM blocks WHERE custom-status = 'done' }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE custom-status = 'done'\">\n<p id=\"20201105103725-aaaaaaa\" custom-status=\"done\">已完成</p>\n</div>\n"},
+	{"2", "!{{ SELECT * FROM blocks WHERE tag = '待办' }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE tag = '待办'\">\n<p id=\"20201105103725-bbbbbbb\"><em>#待办#</em> 买牛奶</p>\n</div>\n"},
+	{"1", "!{{ SELECT * FROM blocks WHERE type = 'p' AND content LIKE '%牛奶%' LIMIT 1 }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE type = 'p' AND content LIKE '%牛奶%' LIMIT 1\">\n<p id=\"20201105103725-bbbbbbb\"><em>#待办#</em> 买牛奶</p>\n</div>\n"},
+	{"0", "!{{ SELECT * FROM blocks WHERE type = 'NodeHeading' }}", "<div data-type=\"block-query-embed\" data-script=\"SELECT * FROM blocks WHERE type = 'NodeHeading'\">\n<h1>foo</h1>\n<h2>bar</h2>\n</div>\n"},
+}
+
+func TestBlockQueryExecutor(t *testing.T) {
+	options := parse.NewOptions()
+	options.KramdownBlockIAL = true
+	options.Tag = true
+	trees := []*parse.Tree{
+		parse.Parse("", []byte("# foo\n\n## bar\n\n已完成\n{: id=\"20201105103725-aaaaaaa\" custom-status=\"done\"}\n\n#待办# 买牛奶\n{: id=\"20201105103725-bbbbbbb\"}\n\n买更多牛奶\n"), options),
+	}
+
+	luteEngine := lute.New()
+	luteEngine.ParseOptions.BlockRef = true
+	luteEngine.QueryExecutor = query.NewMemExecutor(trees...)
+	for _, test := range blockQueryExecutorTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
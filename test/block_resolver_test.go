@@ -0,0 +1,77 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+)
+
+var blockResolverTests = []parseTest{
+
+	{"5", "!((20201105103725-aaaaaaa))", "<div data-type=\"block-embed\" data-id=\"20201105103725-aaaaaaa\">\n<p>foo <em>bar</em></p>\n</div>\n"},
+	{"4", "!((20201105103725-ccccccc))", "<div data-type=\"block-embed\" data-id=\"20201105103725-ccccccc\">\n<div data-type=\"block-embed\" data-id=\"20201105103725-ccccccc\" data-cycle=\"true\"></div>\n</div>\n"},
+	{"3", "!((20201105103725-zzzzzzz \"foo\"))", "<div data-type=\"block-embed\" data-id=\"20201105103725-zzzzzzz\" data-dangling=\"true\">foo</div>\n"},
+	{"2", "((20201105103725-zzzzzzz))", "<p><span data-type=\"block-ref\" data-id=\"20201105103725-zzzzzzz\" data-dangling=\"true\">20201105103725-zzzzzzz</span></p>\n"},
+	{"1", "((20201105103725-bbbbbbb \"baz\"))", "<p><span data-type=\"block-ref\" data-id=\"20201105103725-bbbbbbb\">baz</span></p>\n"},
+	{"0", "((20201105103725-bbbbbbb))", "<p><span data-type=\"block-ref\" data-id=\"20201105103725-bbbbbbb\">标题 &lt;1&gt;</span></p>\n"},
+}
+
+func TestBlockResolver(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.ParseOptions.BlockRef = true
+	luteEngine.BlockResolver = render.BlockResolverFunc(func(id string) (*ast.Node, string) {
+		switch id {
+		case "20201105103725-aaaaaaa":
+			return nil, "foo *bar*"
+		case "20201105103725-bbbbbbb":
+			return nil, "# 标题 <1>"
+		case "20201105103725-ccccccc":
+			return nil, "!((20201105103725-ccccccc))"
+		}
+		return nil, ""
+	})
+	for _, test := range blockResolverTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestBlockResolverKeepsTree(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.ParseOptions.BlockRef = true
+	resolver := render.BlockResolverFunc(func(id string) (*ast.Node, string) {
+		return nil, "# 标题"
+	})
+
+	markdown := "((20201105103725-bbbbbbb))\n"
+	tree := parse.Parse("", []byte(markdown), luteEngine.ParseOptions)
+	expected := "<p><span data-type=\"block-ref\" data-id=\"20201105103725-bbbbbbb\">标题</span></p>\n"
+	for i := 0; i < 2; i++ {
+		renderer := render.NewHtmlRenderer(tree, luteEngine.RenderOptions)
+		renderer.BlockResolver = resolver
+		if html := string(renderer.Render()); expected != html {
+			t.Fatalf("render [%d] failed\nexpected\n\t%q\ngot\n\t%q", i, expected, html)
+		}
+	}
+	// 渲染时解析锚文本不能修改语法树
+	pristine := parse.Parse("", []byte(markdown), luteEngine.ParseOptions)
+	expected = string(render.NewFormatRenderer(pristine, luteEngine.RenderOptions).Render())
+	if formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render()); expected != formatted {
+		t.Fatalf("format after render failed\nexpected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+}
@@ -0,0 +1,159 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	. "github.com/sunlightcs/lute/builder"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+)
+
+type builderTest struct {
+	name string
+	doc  *ast.Node
+	to   string
+}
+
+var builderTests = []builderTest{
+
+	{"8", Document(Heading(2, Text("foo #")), Paragraph(Text("1. foo &copy; x"), SoftBreak(), Text("2) bar"))),
+		"## foo \\#\n\n1\\. foo \\&copy; x\n2\\) bar\n"},
+	{"7", Document(Paragraph(Code("a``b"), Text(" "), Code("`x`"), Text(" "), Code("``"))),
+		"```a``b``` `` `x` `` ``` `` ```\n"},
+	{"6", Document(Paragraph(Text("# 1 * 2 = [x] `y` $3")), BulletList(TaskItem(true, Paragraph(Text("done"))), TaskItem(false, Paragraph(Text("todo"))))),
+		"\\# 1 \\* 2 = \\[x\\] \\`y\\` \\$3\n\n* [X] done\n* [ ] todo\n"},
+	{"5", Document(Table([]int{1, 3}, Row(Cell(Text("名称")), Cell(Text("数量"))), Row(Cell(Text("苹果")), Cell(Text("12"))), Row(Cell(Text("a|b"))))),
+		"| 名称 | 数量 |\n| :--- | ---: |\n| 苹果 |   12 |\n| a\\|b  |      |\n"},
+	{"4", Document(CodeBlock("go", "fmt.Println(\"```\")"), MathBlock("a^2"), ThematicBreak()),
+		"````go\nfmt.Println(\"```\")\n````\n\n$$\na^2\n$$\n\n---\n"},
+	{"3", Document(Blockquote(Paragraph(Text("foo"), SoftBreak(), Text("bar")), Blockquote(Paragraph(Text("baz"))))),
+		"> foo\n> bar\n>\n>> baz\n>>\n"},
+	{"2", Document(OrderedList(3, Item(Paragraph(Text("foo"))), Item(Paragraph(Text("bar")), BulletList(Item(Paragraph(Text("baz"))))))),
+		"3. foo\n4. bar\n   * baz\n"},
+	{"1", Document(Paragraph(Link("https://b3log.org", "B3log", Text("链接 "), Strong(Text("粗体"))), Text(" "), Image("/a b.png", "图片", ""), HardBreak(), Emphasis(Text("em")), Text(" "), Strikethrough(Text("del")), Text(" "), Code("a`b"), Text(" "), InlineMath("x"))),
+		"[链接 **粗体**](https://b3log.org \"B3log\") ![图片](/a%20b.png)\n*em* ~~del~~ ``a`b`` $x$\n"},
+	{"0", Document(Heading(2, Text("Release "), Emphasis(Text("Notes"))), Paragraph(Text("foo"))),
+		"## Release *Notes*\n\nfoo\n"},
+}
+
+func TestBuilder(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAutoSpace(false)
+	for _, test := range builderTests {
+		tree := Tree(test.doc, luteEngine.ParseOptions)
+		formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render())
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, formatted)
+		}
+
+		// 构造出的语法树和解析格式化结果得到的语法树应该渲染出同样的 HTML
+		html := string(render.NewHtmlRenderer(tree, luteEngine.RenderOptions).Render())
+		expected := luteEngine.MarkdownStr(test.name, formatted)
+		if expected != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, expected, html)
+		}
+	}
+}
+
+func TestBuilderRoundTrip(t *testing.T) {
+	blocks := []*ast.Node{
+		Paragraph(Text("1. foo")),
+		Paragraph(Text("foo"), SoftBreak(), Text("10) bar")),
+		Heading(2, Text("foo #")),
+		Heading(1, Text("#")),
+		Paragraph(Text("&copy; x &#35; y")),
+		Paragraph(Code("a``b"), Text(" "), Code("`"), Text(" "), Code("``x")),
+	}
+
+	luteEngine := lute.New()
+	luteEngine.SetAutoSpace(false)
+	for i, block := range blocks {
+		typ, text := block.Type, builderText(block)
+		tree := Tree(block, luteEngine.ParseOptions)
+		formatted := render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render()
+
+		// 重新解析后应该得到同样类型的块和同样的文本
+		reparsed := parse.Parse("", formatted, luteEngine.ParseOptions).Root.FirstChild
+		if typ != reparsed.Type || text != builderText(reparsed) {
+			t.Fatalf("test case [%d] failed\nexpected\n\t%s %q\ngot\n\t%s %q\nformatted\n\t%q", i, typ, text, reparsed.Type, builderText(reparsed), formatted)
+		}
+	}
+}
+
+// builderText 返回节点 n 下的文本，转义字符和行级代码也包含在内。
+func builderText(n *ast.Node) string {
+	buf := &strings.Builder{}
+	ast.Walk(n, func(c *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		switch c.Type {
+		case ast.NodeText, ast.NodeBackslashContent, ast.NodeCodeSpanContent:
+			buf.Write(c.Tokens)
+		case ast.NodeSoftBreak:
+			buf.WriteByte('\n')
+		}
+		return ast.WalkContinue
+	})
+	return buf.String()
+}
+
+func TestNodeClone(t *testing.T) {
+	ast.Testing = true
+	defer func() { ast.Testing = false }()
+
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	tree := parse.Parse("", []byte("foo[^1]\n{: id=\"20201111111111-aaaaaaa\"}\n\n[^1]: bar\n"), luteEngine.ParseOptions)
+	clone := tree.Root.Clone(true)
+	clone.FirstChild.FirstChild.Tokens[0] = 'F'
+	if "foo" != tree.Root.FirstChild.FirstChild.TokensStr() {
+		t.Fatalf("clone shares tokens with the original node")
+	}
+	if "20201111111111-aaaaaaa" != tree.Root.FirstChild.IALAttr("id") {
+		t.Fatalf("clone changed the original IAL")
+	}
+	if id := clone.FirstChild.IALAttr("id"); "20060102150405-1a2b3c4" != id {
+		t.Fatalf("unexpected fresh id [%s]", id)
+	}
+	if ial := clone.FirstChild.Next.TokensStr(); "{: id=\"20060102150405-1a2b3c4\"}\n" != ial {
+		t.Fatalf("unexpected cloned IAL node [%s]", ial)
+	}
+	def := clone.ChildByType(ast.NodeFootnotesDefBlock).FirstChild
+	if 1 != len(def.FootnotesRefs) || clone.FirstChild.FirstChild.Next != def.FootnotesRefs[0] {
+		t.Fatalf("footnotes refs are not remapped to the cloned nodes")
+	}
+
+	same := tree.Root.Clone(false)
+	if "20201111111111-aaaaaaa" != same.FirstChild.IALAttr("id") {
+		t.Fatalf("clone without fresh ids changed the id")
+	}
+}
+
+func TestNodeReplaceWithAndWrap(t *testing.T) {
+	luteEngine := lute.New()
+	tree := parse.Parse("", []byte("foo\n\nbar\n\nbaz\n"), luteEngine.ParseOptions)
+	bar := tree.Root.FirstChild.Next
+	bar.ReplaceWith(Heading(1, Text("bar1")), Paragraph(Text("bar2")))
+	tree.Root.LastChild.Wrap(Blockquote())
+	foo := tree.Root.FirstChild
+	foo.ReplaceWith(BulletList(Item(foo.Clone(false))))
+	formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render())
+	expected := "* foo\n\n# bar1\n\nbar2\n\n> baz\n"
+	if expected != formatted {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+}
@@ -0,0 +1,71 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var chinesePunctTests = []parseTest{
+
+	{"6", "`中文, 中文` [中文, 中文](https://b3log.org/a,b) 中文, 中文\n", "`中文, 中文` [中文, 中文](https://b3log.org/a,b) 中文，中文\n"},
+	{"5", "中文...中文 版本1.0, Lute is great. it's 'Lute'\n", "中文...中文 版本1.0, Lute is great. it's 'Lute'\n"},
+	{"4", "”反了“ 和 “对的”\n", "“反了” 和 “对的”\n"},
+	{"3", "他说\"你好\"，'中文'，it's ok\n", "他说“你好”，‘中文’，it's ok\n"},
+	{"2", "太好了！！！真的？？是的。。\n\n测试……——\n", "太好了！真的？是的。\n\n测试……——\n"},
+	{"1", "ＡＢＣ１２３中文\n", "ABC123中文\n"},
+	{"0", "# 标题!!\n\n中文, 中文.中文!中文\n\n中文(注释)中文, Lute(一款引擎)\n", "# 标题！\n\n中文，中文。中文！中文\n\n中文（注释）中文, Lute（一款引擎）\n"},
+}
+
+func TestChinesePunct(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetChinesePunct(true)
+	for _, test := range chinesePunctTests {
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] failed\nformat is not idempotent\n\t%q", test.name, again)
+		}
+	}
+}
+
+var chinesePunctLintTests = []parseTest{
+
+	{"1", "中文，中文\n\n```\n中文, 中文\n```\n", ""},
+	{"0", "# 标题!!\n\n第一行, 中文\n他说\"你好\"。 **粗体(注释)中文**\n\n- 列表１２３\n", "1:5: chinese-punctuation: punctuation \"!!\" should be \"！\"\n3:4: chinese-punctuation: punctuation \", \" should be \"，\"\n4:3: chinese-punctuation: punctuation \"\\\"\" should be \"“\"\n4:6: chinese-punctuation: punctuation \"\\\"\" should be \"”\"\n4:13: chinese-punctuation: punctuation \"(\" should be \"（\"\n4:16: chinese-punctuation: punctuation \")\" should be \"）\"\n6:5: chinese-punctuation: punctuation \"１２３\" should be \"123\"\n"},
+}
+
+func TestChinesePunctLint(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.LintOptions.Rules[lute.LintCJKSpacing] = false
+	luteEngine.LintOptions.Rules[lute.LintFenceLanguage] = false
+	for _, test := range chinesePunctLintTests {
+		diagnostics, err := luteEngine.Lint(test.from)
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		buf := &strings.Builder{}
+		for _, diagnostic := range diagnostics {
+			buf.WriteString(diagnostic.String() + "\n")
+		}
+		if got := buf.String(); test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, got, test.from)
+		}
+		if diagnostics, _ = luteEngine.Lint(luteEngine.Fix(test.from)); 0 < len(diagnostics) {
+			t.Fatalf("test case [%s] failed: fixed markdown still has problem [%s]", test.name, diagnostics[0])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute/diff"
+	"github.com/sunlightcs/lute/parse"
+)
+
+var diffTests = []struct {
+	name     string
+	old, new string
+	changes  string
+}{
+
+	{"3", "foo bar baz\n\n第二段\n", "foo qux baz\n\n第二段\n", `[{"op":"modify","type":"NodeParagraph","oldIndex":0,"newIndex":0,"old":"foo bar baz","new":"foo qux baz","inlines":[{"op":"equal","text":"foo "},{"op":"delete","text":"bar"},{"op":"insert","text":"qux"},{"op":"equal","text":" baz"}]}]`},
+	{"2", "foo\n{: id=\"20201105103725-aaaaaaa\"}\n\nbar\n{: id=\"20201105103725-bbbbbbb\"}\n\nbaz\n{: id=\"20201105103725-ccccccc\"}\n", "baz\n{: id=\"20201105103725-ccccccc\"}\n\nfoo\n{: id=\"20201105103725-aaaaaaa\"}\n\nbar\n{: id=\"20201105103725-bbbbbbb\"}\n", `[{"op":"move","id":"20201105103725-ccccccc","type":"NodeParagraph","oldIndex":2,"newIndex":0}]`},
+	{"1", "foo\n{: id=\"20201105103725-aaaaaaa\"}\n\nbar\n{: id=\"20201105103725-bbbbbbb\"}\n", "foo\n{: id=\"20201105103725-aaaaaaa\"}\n\n## baz\n{: id=\"20201105103725-ccccccc\"}\n", `[{"op":"delete","id":"20201105103725-bbbbbbb","type":"NodeParagraph","oldIndex":1,"newIndex":-1,"old":"bar"},{"op":"insert","id":"20201105103725-ccccccc","type":"NodeHeading","oldIndex":-1,"newIndex":1,"new":"## baz"}]`},
+	{"0", "思源笔记\n{: id=\"20201105103725-aaaaaaa\"}\n", "思源**笔记**本\n{: id=\"20201105103725-aaaaaaa\"}\n", `[{"op":"modify","id":"20201105103725-aaaaaaa","type":"NodeParagraph","oldIndex":0,"newIndex":0,"old":"思源笔记","new":"思源**笔记**本","inlines":[{"op":"equal","text":"思源"},{"op":"insert","text":"**"},{"op":"equal","text":"笔记"},{"op":"insert","text":"**本"}]}]`},
+}
+
+func TestDiff(t *testing.T) {
+	options := parse.NewOptions()
+	options.KramdownBlockIAL = true
+	for _, test := range diffTests {
+		oldTree := parse.Parse("", []byte(test.old), options)
+		newTree := parse.Parse("", []byte(test.new), options)
+		data, err := json.Marshal(diff.Diff(oldTree, newTree))
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		if changes := string(data); test.changes != changes {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%s\ngot\n\t%s", test.name, test.changes, changes)
+		}
+	}
+}
+
+func TestDiffInlineLong(t *testing.T) {
+	var oldWords, newWords []string
+	for i := 0; i < 2000; i++ {
+		oldWords = append(oldWords, "w"+strconv.Itoa(i))
+		if 1000 == i {
+			newWords = append(newWords, "x")
+			continue
+		}
+		newWords = append(newWords, "w"+strconv.Itoa(i))
+	}
+	oldText, newText := strings.Join(oldWords, " "), strings.Join(newWords, " ")
+
+	changes := diff.Inline(oldText, newText)
+	if 4 != len(changes) || "delete" != changes[1].Op || "w1000" != changes[1].Text || "insert" != changes[2].Op || "x" != changes[2].Text {
+		data, _ := json.Marshal(changes)
+		t.Fatalf("unexpected inline changes %s", data)
+	}
+	var gotOld, gotNew string
+	for _, change := range changes {
+		if "insert" != change.Op {
+			gotOld += change.Text
+		}
+		if "delete" != change.Op {
+			gotNew += change.Text
+		}
+	}
+	if oldText != gotOld || newText != gotNew {
+		t.Fatalf("inline changes do not rebuild the texts")
+	}
+}
@@ -0,0 +1,61 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+)
+
+var formatMinimalTests = []parseTest{
+
+	{"2", "# Title\n\n* a\n* b\n\n---\n\nfoo\n\nbar\n", "# Title\n\n- a\n- b\n\n---\n\nbar\n"},
+	{"1", "Title\n=====\n\n*  item   one\n*  item two\n\n\n\n| a | b |\n|--|--|\n| ccc | d |\n\n1. x\n1. y\n# Heading\nno trailing newline", "Title\n=====\n\n*  item   one\n*  item two\n\n\n\n| a | b |\n|--|--|\n| ccc | d |\n\n1. x\n2. y\n\n# Heading\nno trailing newline"},
+	{"0", "foo  bar\n\nLute中文\n\n***\n", "foo  bar\n\nLute 中文\n\n***\n"},
+}
+
+func TestFormatMinimal(t *testing.T) {
+	for _, test := range formatMinimalTests {
+		luteEngine := lute.New()
+		luteEngine.SetAutoSpace(true)
+		if "2" == test.name {
+			style := render.NewFormatStyle()
+			style.BulletMarkers = "-"
+			luteEngine.SetFormatStyle(style)
+			luteEngine.Transformers = []lute.Transformer{func(tree *parse.Tree) error {
+				// 删除内容为 foo 的段落
+				ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+					if entering && ast.NodeParagraph == n.Type && "foo" == n.Text() {
+						n.Unlink()
+						return ast.WalkStop
+					}
+					return ast.WalkContinue
+				})
+				return nil
+			}}
+		}
+
+		formatted := luteEngine.FormatMinimalStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatMinimalStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] failed\nformat is not idempotent\n\t%q", test.name, again)
+		}
+		if full := luteEngine.FormatStr(test.name, test.from); full != luteEngine.FormatMinimalStr(test.name, full) {
+			t.Fatalf("test case [%s] failed\nminimal format changes formatted text\n\t%q", test.name, full)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/render"
+)
+
+var formatStyleTests = []parseTest{
+
+	{"5", "| a | bbbbbb |\n|:-|-:|\n| cccccc | d |\n\n+ foo\\@bar.com a <\\/b> c\\;\n", "| a      | bbbbbb |\n| :----- | -----: |\n| cccccc |      d |\n\n- foo\\@bar.com a <\\/b> c;\n"},
+	{"3", "- a\n- b\n\n* c\n* d\n\n+ e\n\n1. f\n\n- g\n", "- a\n- b\n\n* c\n* d\n\n- e\n\n1. f\n\n- g\n"},
+	{"2", "Title\n===\n\n3. x\n3. y\n\n```go\n```` nested\n```\n", "# Title\n\n3. x\n3. y\n\n`````go\n```` nested\n`````\n"},
+	{"1", "| a | bbbbbb |\n|:-|-:|\n| cccccc | d |\n\nfoo\\, bar\\* baz\\?\n\n---\n", "| a | bbbbbb |\n| :-- | --: |\n| cccccc | d |\n\nfoo, bar\\* baz?\n\n***\n"},
+	{"0", "# Title\n\n### Sub\n\n* a *em* **strong** foo*bar*baz\n  + b\n    * c\n\n3. x\n4. y\n\n```go\ncode\n```\n\n    indented\n", "Title\n=====\n\n### Sub\n\n- a _em_ __strong__ foo*bar*baz\n  * b\n    - c\n\n1. x\n1. y\n\n~~~~go\ncode\n~~~~\n\n~~~~\nindented\n~~~~\n"},
+}
+
+func TestFormatStyle(t *testing.T) {
+	for _, test := range formatStyleTests {
+		luteEngine := lute.New()
+		style := render.NewFormatStyle()
+		switch test.name {
+		case "0":
+			style.BulletMarkers = "-*"
+			style.EmphasisMarker = "_"
+			style.StrongMarker = "__"
+			style.HeadingStyle = render.HeadingStyleSetext
+			style.OrderedListNumbering = render.OrderedListNumberingOne
+			style.FenceChar = "~"
+			style.FenceLength = 4
+		case "1":
+			style.NoTablePadding = true
+			style.Escape = render.EscapeMinimal
+			style.ThematicBreak = "***"
+		case "2":
+			style.HeadingStyle = render.HeadingStyleATX
+			style.OrderedListNumbering = render.OrderedListNumberingPreserve
+			style.FenceLength = 3
+		case "3":
+			style.BulletMarkers = "-"
+		case "5":
+			// 零值字段保持默认行为
+			style = &render.FormatStyle{BulletMarkers: "-", Escape: render.EscapeMinimal}
+		}
+		luteEngine.SetFormatStyle(style)
+
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] failed\nformat is not idempotent\n\t%q", test.name, again)
+		}
+		if "3" == test.name {
+			// 相邻的列表格式化后不能合并
+			if expected, got := luteEngine.MarkdownStr(test.name, test.from), luteEngine.MarkdownStr(test.name, formatted); expected != got {
+				t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, expected, got, test.from)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var formatWrapTests = []parseTest{
+
+	{"5", "one\ntwo three\n中文\n段落\n\n- a\n  b\n\n> c\n> d\n", "one two three 中文段落\n\n- a b\n\n> c d\n"},
+	{"4", "aaaa bbbb - cccc # dddd 1. eeee\n", "aaaa bbbb - cccc #\ndddd 1. eeee\n"},
+	{"3", "- [ ] task item with quite a few words\n\n> quote with quite a few words in it to wrap around\n", "- [ ] task item with\n  quite a few words\n\n> quote with quite a\n> few words in it to\n> wrap around\n"},
+	{"2", "hard break here  \nnext line with many words to be wrapped\n", "hard break here\\\nnext line with many\nwords to be wrapped\n"},
+	{"1", "中文的段落需要按照宽度折行，标点符号不能出现在行首。「引号」也不能拆开……省略号也不行。\n", "中文的段落需要按照宽\n度折行，标点符号不能\n出现在行首。「引号」\n也不能拆开……省略号也\n不行。\n"},
+	{"0", "The quick brown fox jumps over the lazy dog and `some code span` then $a + b = c$ and [link text](https://example.com/a-b \"title here\") end.\n", "The quick brown fox\njumps over the lazy\ndog and\n`some code span`\nthen $a + b = c$ and\n[link\ntext](https://example.com/a-b \"title here\")\nend.\n"},
+}
+
+func TestFormatWrap(t *testing.T) {
+	for _, test := range formatWrapTests {
+		luteEngine := lute.New()
+		if "5" == test.name {
+			luteEngine.SetFormatUnwrap(true)
+		} else {
+			luteEngine.SetFormatWrapWidth(20)
+		}
+
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] failed\nformat is not idempotent\n\t%q", test.name, again)
+		}
+	}
+}
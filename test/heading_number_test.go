@@ -0,0 +1,81 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var headingNumberTests = []parseTest{
+
+	{"5", "[toc]\n\n# 1 foo\n\n## 1.1 bar\n", "<div class=\"vditor-toc\" data-block=\"0\" data-type=\"toc-block\" contenteditable=\"false\"><ul><li><span data-target-id=\"1-foo\">1 foo</span><ul><li><span data-target-id=\"1-1-bar\">1.1 bar</span></li></ul></li></ul></div>\n<h1 id=\"1-foo\">1 foo</h1>\n<h2 id=\"1-1-bar\">1.1 bar</h2>\n"},
+	{"4", "[toc]\n\n# foo\n\n## bar\n", "<div class=\"vditor-toc\" data-block=\"0\" data-type=\"toc-block\" contenteditable=\"false\"><ul><li><span data-target-id=\"foo\">1 foo</span><ul><li><span data-target-id=\"bar\">1.1 bar</span></li></ul></li></ul></div>\n<h1 id=\"foo\">1 foo</h1>\n<h2 id=\"bar\">1.1 bar</h2>\n"},
+	{"3", "# foo\n\n## bar\n{: number-exclude=\"true\"}\n\n## baz\n", "<h1 id=\"foo\">1 foo</h1>\n<h2 id=\"bar\">bar</h2>\n<h2 id=\"baz\">1.1 baz</h2>\n"},
+	{"2", "# foo\n\n## bar\n\n### baz\n\n## qux\n", "<h1 id=\"foo\">foo</h1>\n<h2 id=\"bar\">1 bar</h2>\n<h3 id=\"baz\">1.1 baz</h3>\n<h2 id=\"qux\">2 qux</h2>\n"},
+	{"1", "# 绪论\n\n## 背景\n\n# 方法\n\n## 数据\n\n## 模型\n", "<h1 id=\"绪论\">第一章 绪论</h1>\n<h2 id=\"背景\">1.1 背景</h2>\n<h1 id=\"方法\">第二章 方法</h1>\n<h2 id=\"数据\">2.1 数据</h2>\n<h2 id=\"模型\">2.2 模型</h2>\n"},
+	{"0", "# foo\n\n## bar\n\n### baz\n\n## qux\n\n# quux\n", "<h1 id=\"foo\">1 foo</h1>\n<h2 id=\"bar\">1.1 bar</h2>\n<h3 id=\"baz\">1.1.1 baz</h3>\n<h2 id=\"qux\">1.2 qux</h2>\n<h1 id=\"quux\">2 quux</h1>\n"},
+}
+
+func TestHeadingNumber(t *testing.T) {
+	for _, test := range headingNumberTests {
+		luteEngine := lute.New()
+		luteEngine.SetHeadingID(true)
+		luteEngine.SetHeadingNumber(true)
+		switch test.name {
+		case "1":
+			luteEngine.SetHeadingNumberStyle("chinese")
+		case "2":
+			luteEngine.SetHeadingNumberStartLevel(2)
+		case "3":
+			luteEngine.SetKramdownIAL(true)
+		case "4", "5":
+			luteEngine.ParseOptions.ToC = true
+		}
+
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var formatHeadingNumberTests = []parseTest{
+
+	{"5", "# 1 foo\n\n## 1.2 b\n{: number-exclude=\"true\"}\n\n## 1.1 a\n\n{: id=\"20200813131152-0wk5akh\" type=\"doc\"}\n", "# 1 foo\n\n## b\n{: number-exclude=\"true\"}\n\n## 1.1 a\n\n\n{: id=\"20200813131152-0wk5akh\" type=\"doc\"}\n"},
+	{"4", "# 1 foo\n\n## 1.2 b\n\n## 1.1 a\n", "# 1 foo\n\n## 1.1 b\n\n## 1.2 a\n"},
+	{"3", "# 1 foo\n\n## new\n\n## 1.1 a\n\n## 1.2 b\n", "# 1 foo\n\n## 1.1 new\n\n## 1.2 a\n\n## 1.3 b\n"},
+	{"2", "第三章 绪论\n===\n\n## 1.5 背景\n", "第一章 绪论\n===========\n\n## 1.1 背景\n"},
+	{"1", "# 1 foo\n\n## 1.1 bar\n\n## 1.2 baz\n", "# 1 foo\n\n## 1.1 bar\n\n## 1.2 baz\n"},
+	{"0", "# 3. foo\n\n## bar\n\n### 2.7 **baz**\n\n# qux\n", "# 1 foo\n\n## 1.1 bar\n\n### 1.1.1 **baz**\n\n# 2 qux\n"},
+}
+
+func TestFormatHeadingNumber(t *testing.T) {
+	for _, test := range formatHeadingNumberTests {
+		luteEngine := lute.New()
+		luteEngine.SetHeadingNumber(true)
+		switch test.name {
+		case "2":
+			luteEngine.SetHeadingNumberStyle("chinese")
+		case "5":
+			luteEngine.SetKramdownIAL(true)
+		}
+
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] failed\nformat is not idempotent\n\t%q", test.name, again)
+		}
+	}
+}
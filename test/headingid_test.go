@@ -11,9 +11,11 @@
 package test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/parse"
 )
 
 var headingIDTests = []parseTest{
@@ -37,6 +39,39 @@ func TestHeadingID(t *testing.T) {
 	}
 }
 
+var headingIDSlugTests = []parseTest{
+
+	{"5", "# 😄\n\n## 😄\n", "<h1 id=\"heading\">😄</h1>\n<h2 id=\"heading-1\">😄</h2>\n"},
+	{"4", "# a\n\n# a\n", "<h1 id=\"A\">a</h1>\n<h1 id=\"A-1\">a</h1>\n"},
+	{"3", "# foo\n\n# bar {#foo}\n\n# foo\n", "<h1 id=\"foo-1\">foo</h1>\n<h1 id=\"foo\">bar</h1>\n<h1 id=\"foo-2\">foo</h1>\n"},
+	{"2", "# 中文标题\n\n## Lute 引擎v2\n\n## 中文标题\n", "<h1 id=\"zhong-wen-biao-ti\">中文标题</h1>\n<h2 id=\"lute-yin-qing-v2\">Lute 引擎v2</h2>\n<h2 id=\"zhong-wen-biao-ti-1\">中文标题</h2>\n"},
+	{"1", "# Hello -- World\n\n## 123\n\n## Hello - World\n", "<h1 id=\"hello-world\">Hello -- World</h1>\n<h2 id=\"anchor-123\">123</h2>\n<h2 id=\"hello-world-1\">Hello - World</h2>\n"},
+	{"0", "# Hello, World!\n\n## Hello World\n\n## `foo.bar` 中文 😄\n", "<h1 id=\"hello-world\">Hello, World!</h1>\n<h2 id=\"hello-world-1\">Hello World</h2>\n<h2 id=\"foobar-中文-\"><code>foo.bar</code> 中文 😄</h2>\n"},
+}
+
+func TestHeadingIDSlug(t *testing.T) {
+	for _, test := range headingIDSlugTests {
+		luteEngine := lute.New()
+		luteEngine.SetHeadingID(true)
+		switch test.name {
+		case "0", "3", "5":
+			luteEngine.SetHeadingIDSlug("github")
+		case "1":
+			luteEngine.SetHeadingIDSlug("gitlab")
+		case "2":
+			luteEngine.SetHeadingIDSlug("pinyin")
+		case "4":
+			luteEngine.SetHeadingIDSlug("github")
+			luteEngine.SetHeadingIDSlugFunc(strings.ToUpper)
+		}
+
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
 var headingAnchorTests = []parseTest{
 
 	// 为标题添加锚点 https://github.com/b3log/lute/issues/35
@@ -44,6 +79,27 @@ var headingAnchorTests = []parseTest{
 	{"39", "# 标题一\n", "<h1 id=\"标题一\">标题一<a id=\"vditorAnchor-标题一\" class=\"vditor-anchor\" href=\"#标题一\"><svg viewBox=\"0 0 16 16\" version=\"1.1\" width=\"16\" height=\"16\"><path fill-rule=\"evenodd\" d=\"M4 9h1v1H4c-1.5 0-3-1.69-3-3.5S2.55 3 4 3h4c1.45 0 3 1.69 3 3.5 0 1.41-.91 2.72-2 3.25V8.59c.58-.45 1-1.27 1-2.09C10 5.22 8.98 4 8 4H4c-.98 0-2 1.22-2 2.5S3 9 4 9zm9-3h-1v1h1c1 0 2 1.22 2 2.5S13.98 12 13 12H9c-.98 0-2-1.22-2-2.5 0-.83.42-1.64 1-2.09V6.25c-1.09.53-2 1.84-2 3.25C6 11.31 7.55 13 9 13h4c1.45 0 3-1.69 3-3.5S14.5 6 13 6z\"></path></svg></a></h1>\n"},
 }
 
+func TestHeadingIDSlugCache(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetHeadingID(true)
+	tree := parse.Parse("", []byte("# Foo Bar\n\n# Foo Bar\n"), luteEngine.ParseOptions)
+	heading := tree.Root.LastChild
+	if id := parse.HeadingID(heading); "Foo-Bar-" != id {
+		t.Fatalf("unexpected default id: %q", id)
+	}
+
+	// 切换生成方式后不能用到默认方式生成的 ID
+	tree.Context.ParseOption.HeadingIDSlug = parse.HeadingIDSlugGitHub
+	if id := tree.HeadingID(heading); "foo-bar-1" != id {
+		t.Fatalf("unexpected github id: %q", id)
+	}
+	tree.Context.ParseOption.HeadingIDSlug = parse.HeadingIDSlugGitLab
+	heading.FirstChild.Tokens = []byte("Foo -- Bar")
+	if id := tree.HeadingID(heading); "foo-bar-1" != id {
+		t.Fatalf("unexpected gitlab id: %q", id)
+	}
+}
+
 func TestHeadingAnchor(t *testing.T) {
 	luteEngine := lute.New()
 	luteEngine.SetHeadingID(true)
@@ -0,0 +1,86 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+func TestIndex(t *testing.T) {
+	options := parse.NewOptions()
+	options.KramdownBlockIAL = true
+	options.BlockRef = true
+	tree := parse.Parse("", []byte("foo\n{: id=\"20201105103725-aaaaaaa\"}\n\n* bar\n  {: id=\"20201105103725-bbbbbbb\"}\n{: id=\"20201105103725-ccccccc\"}\n"), options)
+	defer tree.Index().Release()
+
+	index := tree.Index()
+	foo := index.Get("20201105103725-aaaaaaa")
+	if nil == foo || ast.NodeParagraph != foo.Type || "foo" != foo.Text() {
+		t.Fatalf("index get [20201105103725-aaaaaaa] failed")
+	}
+	list := index.Get("20201105103725-ccccccc")
+	if nil == list || ast.NodeList != list.Type {
+		t.Fatalf("index get [20201105103725-ccccccc] failed")
+	}
+
+	list.Unlink()
+	if nil != index.Get("20201105103725-ccccccc") || nil != index.Get("20201105103725-bbbbbbb") {
+		t.Fatalf("index should not contain unlinked nodes")
+	}
+
+	foo.InsertAfter(list)
+	if list != index.Get("20201105103725-ccccccc") || nil == index.Get("20201105103725-bbbbbbb") {
+		t.Fatalf("index should contain inserted nodes")
+	}
+
+	p := &ast.Node{Type: ast.NodeParagraph, KramdownIAL: [][]string{{"id", "20201105103725-ddddddd"}}}
+	tree.Root.AppendChild(p)
+	if p != index.Get("20201105103725-ddddddd") {
+		t.Fatalf("index should contain appended node")
+	}
+	p.SetIALAttr("id", "20201105103725-eeeeeee")
+	if nil != index.Get("20201105103725-ddddddd") || p != index.Get("20201105103725-eeeeeee") {
+		t.Fatalf("index should follow id changes")
+	}
+}
+
+func TestIndexPerTree(t *testing.T) {
+	options := parse.NewOptions()
+	options.KramdownBlockIAL = true
+	indexed := parse.Parse("", []byte("foo\n{: id=\"20201105103725-aaaaaaa\"}\n"), options)
+	index := indexed.Index()
+	defer index.Release()
+
+	// 修改没有建立索引的树不能影响其他树的索引
+	other := parse.Parse("", []byte("bar\n{: id=\"20201105103725-aaaaaaa\"}\n"), options)
+	bar := other.Root.FirstChild
+	bar.Unlink()
+	other.Root.AppendChild(bar)
+	if foo := index.Get("20201105103725-aaaaaaa"); nil == foo || "foo" != foo.Text() {
+		t.Fatalf("index of another tree should not be changed")
+	}
+}
+
+func TestBacklinks(t *testing.T) {
+	options := parse.NewOptions()
+	options.BlockRef = true
+	tree := parse.Parse("", []byte("foo ((20201105103725-aaaaaaa)) bar ((20201105103725-bbbbbbb \"baz\"))\n\n!((20201105103725-aaaaaaa))\n"), options)
+	backlinks := tree.Backlinks("20201105103725-aaaaaaa")
+	if 2 != len(backlinks) || ast.NodeBlockRef != backlinks[0].Type || ast.NodeBlockEmbed != backlinks[1].Type {
+		t.Fatalf("backlinks of [20201105103725-aaaaaaa] failed: %v", backlinks)
+	}
+	if 0 != len(tree.Backlinks("20201105103725-zzzzzzz")) {
+		t.Fatalf("backlinks of [20201105103725-zzzzzzz] should be empty")
+	}
+}
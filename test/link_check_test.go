@@ -0,0 +1,48 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/parse"
+)
+
+var checkLinksTests = []parseTest{
+
+	{"3", "See arr[i] and m[i][j], note [1] and [TODO].\n\n- [x] done\n- [ ] todo\n\n[foo][] and [bar][missing] and [ok][Ok]\n\n[ok]: /guide.md\n", "6: link reference [foo] is not defined\n6: link reference [missing] is not defined\n"},
+	{"2", "# 中文\n\n((20200813131152-0wk5akh \"foo\"))\n\nfoo\n{: id=\"20200813131152-0wk5akh\"}\n\n((20200813131152-missing \"bar\"))\n", "8: block ref ((20200813131152-missing)) points to a missing block\n"},
+	{"1", "# Intro\n\n[a](guide.md#Install) [b](guide.md#custom-usage) [c](guide.md#usage)\n[d](/guide.md) [e](missing.png) ![f](../outside.md)\n[g](https://b3log.org) [h](mailto:foo@b3log.org) [i](<guide.md?x=1>)\n", "3: anchor #usage does not match any heading in guide.md\n4: link missing.png points to a missing file\n4: link ../outside.md points outside the root directory\n"},
+	{"0", "# Intro\n\n## 中文\n\nSee [a](#usage), [b](#Intro) and [c](#%E4%B8%AD%E6%96%87).\n\nRefs: [foo] and [bar][baz] and [ok]\nand [^1] and [^2] and [x](.\n\n[ok]: /guide.md\n\n[^1]: defined\n", "5: anchor #usage does not match any heading\n7: link reference [baz] is not defined\n8: footnote [^2] is not defined\n"},
+}
+
+func TestCheckLinks(t *testing.T) {
+	for _, test := range checkLinksTests {
+		luteEngine := lute.New()
+		opts := &lute.LinkCheckOptions{Root: "link-check", CheckLinkedHeadings: true}
+		if "2" == test.name {
+			luteEngine.SetBlockRef(true)
+			luteEngine.SetKramdownIAL(true)
+			opts.CheckBlockRefs = true
+		}
+
+		tree := parse.Parse(test.name, []byte(test.from), luteEngine.ParseOptions)
+		buf := &strings.Builder{}
+		for _, problem := range luteEngine.CheckLinks(tree, opts) {
+			buf.WriteString(problem.String() + "\n")
+		}
+		if got := buf.String(); test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, got, test.from)
+		}
+	}
+}
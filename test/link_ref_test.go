@@ -0,0 +1,71 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var referenceLinksTests = []parseTest{
+
+	{"6", "See [b3log-org] and [B3LOG-org-2], see [docs](https://b3log.org) and [lute](https://b3log.org/2).\n", "See [b3log-org] and [B3LOG-org-2], see [docs][b3log-org-3] and [lute][b3log-org-2-2].\n\n[b3log-org-3]: https://b3log.org\n[b3log-org-2-2]: https://b3log.org/2\n"},
+	{"5", "As shown in [1], see [docs](https://b3log.org) and [2] [x][y].\n", "As shown in [1], see [docs][3] and [2] [x][y].\n\n[3]: https://b3log.org\n"},
+	{"4", "[a](/t \"T\") [c](/t 'say \"hi\"') [d](/t (a\\\\\"b))\n", "[a][t] [c][t-2] [d][t-3]\n\n[t]: /t \"T\"\n[t-2]: /t \"say \\\"hi\\\"\"\n[t-3]: /t \"a\\\\\\\"b\"\n"},
+	{"3", "[a](https://b3log.org/lute) [b](https://b3log.org/lute/) [c](https://www.b3log.org/lute?x=1)\n", "[a][b3log-org-lute] [b][b3log-org-lute-2] [c][b3log-org-lute-x-1]\n\n[b3log-org-lute]: https://b3log.org/lute\n[b3log-org-lute-2]: https://b3log.org/lute/\n[b3log-org-lute-x-1]: https://www.b3log.org/lute?x=1\n"},
+	{"2", "[b3log](https://b3log.org) ![logo](https://b3log.org/logo.png \"Logo\")\n", "[b3log][b3log-org] ![logo][b3log-org-logo-png]\n\n[b3log-org]: https://b3log.org\n[b3log-org-logo-png]: https://b3log.org/logo.png \"Logo\"\n"},
+	{"1", "[old][x] [b3log](https://b3log.org) [foo](https://ld246.com)\n\n[x]: https://ld246.com\n\n- [1](/1)\n", "[old][x] [b3log][1] [foo][x]\n\n- [1][2]\n\n[x]: https://ld246.com\n[1]: https://b3log.org\n[2]: /1\n"},
+	{"0", "[Lute](https://b3log.org/lute \"Lute\") and **[b3log](https://b3log.org)**, again [B3log](https://b3log.org) and [top](#top)\n", "[Lute][1] and **[b3log][2]**, again [B3log][2] and [top][3]\n\n[1]: https://b3log.org/lute \"Lute\"\n[2]: https://b3log.org\n[3]: #top\n"},
+}
+
+func TestReferenceLinks(t *testing.T) {
+	for _, test := range referenceLinksTests {
+		luteEngine := lute.New()
+		labelStyle := lute.LinkRefLabelNumber
+		if "2" <= test.name && "5" != test.name {
+			labelStyle = lute.LinkRefLabelURL
+		}
+		luteEngine.AddTransformer(lute.ReferenceLinks(labelStyle))
+
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if again := luteEngine.FormatStr(test.name, formatted); formatted != again {
+			t.Fatalf("test case [%s] failed\nformat is not idempotent\n\t%q", test.name, again)
+		}
+		if html, expected := luteEngine.MarkdownStr(test.name, test.from), lute.New().MarkdownStr(test.name, test.from); expected != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, expected, html, test.from)
+		}
+	}
+}
+
+var inlineLinksTests = []parseTest{
+
+	{"1", "- [a][x]\n\n  [x]: /url\n\n[b][y]\n\n[y]: /b\n", "- [a](/url)\n\n[b](/b)\n"},
+	{"0", "[a][x] and [x] and [X][] and ![img][y]\n\n[x]: /url \"T\"\n[y]: /img.png\n", "[a](/url \"T\") and [x](/url \"T\") and [X](/url \"T\") and ![img](/img.png)\n"},
+}
+
+func TestInlineLinks(t *testing.T) {
+	for _, test := range inlineLinksTests {
+		luteEngine := lute.New()
+		luteEngine.AddTransformer(lute.InlineLinks())
+
+		formatted := luteEngine.FormatStr(test.name, test.from)
+		if test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+		if html, expected := luteEngine.MarkdownStr(test.name, test.from), lute.New().MarkdownStr(test.name, test.from); expected != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, expected, html, test.from)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var lintTests = []parseTest{
+
+	{"2", "aaaa bbbb cccc dddd eeee ffff\n\n中文中文中文中文中文中文 abc\n\n| aaaa bbbb cccc dddd eeee |\n| - |\n\nhttps://example.com/very/long/link/here\n", "1:21: line-length: line width 29 exceeds 20\n3:11: line-length: line width 28 exceeds 20\n"},
+	{"1", "# Title\n\n```go\ncode  \n```\n\nline  \nbreak\n\n<https://b3log.org>\n", ""},
+	{"0", "# 标题\n\n### Lute中文 \n\n* a\n- b\n\nhttps://b3log.org\n\n```\ncode\n```\n\n![](a.png)\n\n# Second\n", "3: heading-increment: heading level jumps from h1 to h3\n3:11: no-trailing-spaces: trailing spaces\n3: cjk-latin-spacing: missing space between CJK and Latin characters\n6: list-marker-style: list marker '-' differs from '*'\n8: no-bare-urls: bare URL https://b3log.org\n10: fenced-code-language: fenced code block has no language\n14: image-alt-text: image has no alt text\n16: single-h1: multiple top-level headings in the same document\n"},
+}
+
+func TestLint(t *testing.T) {
+	for _, test := range lintTests {
+		luteEngine := lute.New()
+		if "2" == test.name {
+			luteEngine.LintOptions.LineLength = 20
+			luteEngine.LintOptions.Rules[lute.LintBareURL] = false
+		}
+
+		diagnostics, err := luteEngine.Lint(test.from)
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		buf := &strings.Builder{}
+		for _, diagnostic := range diagnostics {
+			buf.WriteString(diagnostic.String() + "\n")
+		}
+		if got := buf.String(); test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, got, test.from)
+		}
+	}
+}
+
+var fixTests = []parseTest{
+
+	{"1", "- a\n  * b\n  + c\n- d\n", "- a\n  - b\n  - c\n- d\n"},
+	{"0", "# Lute中文 \n\n* a\n- b\n+ c\n\nhttps://b3log.org\n", "# Lute 中文\n\n* a\n* b\n* c\n\n[https://b3log.org](https://b3log.org)\n"},
+}
+
+func TestFix(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range fixTests {
+		if got := luteEngine.Fix(test.from); test.to != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, got, test.from)
+		}
+		if diagnostics, _ := luteEngine.Lint(test.to); 0 < len(diagnostics) {
+			t.Fatalf("test case [%s] failed: fixed markdown still has problem [%s]", test.name, diagnostics[0])
+		}
+	}
+}
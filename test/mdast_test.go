@@ -0,0 +1,69 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var mdastTests = []parseTest{
+
+	{"3", "foo[^1] ((20201105103725-aaaaaaa \"t\")) #tag#\n\n[^1]: note\n", "{\"type\":\"root\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"foo\"},{\"type\":\"footnoteReference\",\"identifier\":\"1\",\"label\":\"1\"},{\"type\":\"text\",\"value\":\" \"},{\"type\":\"blockRef\",\"id\":\"20201105103725-aaaaaaa\",\"text\":\"t\"},{\"type\":\"text\",\"value\":\" \"},{\"type\":\"tag\",\"children\":[{\"type\":\"text\",\"value\":\"tag\"}]}]},{\"type\":\"footnoteDefinition\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"note\"}]}],\"identifier\":\"1\",\"label\":\"1\"}]}"},
+	{"2", "| a | b |\n|:-|-|\n| ~~c~~ | $d$ |\n", "{\"type\":\"root\",\"children\":[{\"type\":\"table\",\"children\":[{\"type\":\"tableRow\",\"children\":[{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"a\"}]},{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"b\"}]}]},{\"type\":\"tableRow\",\"children\":[{\"type\":\"tableCell\",\"children\":[{\"type\":\"delete\",\"children\":[{\"type\":\"text\",\"value\":\"c\"}]}]},{\"type\":\"tableCell\",\"children\":[{\"type\":\"inlineMath\",\"value\":\"d\"}]}]}],\"align\":[\"left\",null]}]}"},
+	{"1", "* [x] foo\n* [ ] bar\n", "{\"type\":\"root\",\"children\":[{\"type\":\"list\",\"children\":[{\"type\":\"listItem\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"foo\"}]}],\"spread\":false,\"checked\":true},{\"type\":\"listItem\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"bar\"}]}],\"spread\":false,\"checked\":false}],\"ordered\":false,\"spread\":false}]}"},
+	{"0", "## foo *bar*\nbaz\n", "{\"type\":\"root\",\"children\":[{\"type\":\"heading\",\"children\":[{\"type\":\"text\",\"value\":\"foo \"},{\"type\":\"emphasis\",\"children\":[{\"type\":\"text\",\"value\":\"bar\"}]}],\"depth\":2},{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"baz\"}]}]}"},
+}
+
+func TestMdast(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.ParseOptions.BlockRef = true
+	luteEngine.ParseOptions.Tag = true
+	for _, test := range mdastTests {
+		json := luteEngine.RenderMdast(test.from)
+		if test.to != json {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, json, test.from)
+		}
+	}
+}
+
+var mdastRoundTripTests = []string{
+	"# foo *bar*\n\n> quote\n> **b** `c`\n\n1. a\n2. b\n   * c\n",
+	"* [X] done\n* [ ] todo\n\n| a | b |\n| :- | :-: |\n| 1 | 2 |\n\n```go info\ncode\n```\n\n$$\nx\n$$\n",
+	"[link](/u \"t\") ![img](/i.png) foo[^1] ~~d~~ ==m== #tag# ((id \"t\")) a\\*b 1. x\n\n[^1]: note\n\n{{{row\nfoo\n\nbar\n}}}\n",
+	"1\\. foo \\# bar \\<baz\\> \\_qux\\_\n",
+}
+
+func TestMdastRoundTrip(t *testing.T) {
+	luteEngine := lute.New()
+	for i, markdown := range mdastRoundTripTests {
+		// 导入时会根据 mdast 节点类型开启所需的解析选项
+		exporter := lute.New()
+		exporter.ParseOptions.BlockRef = true
+		exporter.ParseOptions.Tag = true
+		exporter.ParseOptions.SuperBlock = true
+		exporter.ParseOptions.Mark = true
+		json := exporter.RenderMdast(markdown)
+		formatted, err := luteEngine.Mdast2Md(json)
+		if nil != err {
+			t.Fatalf("test case [%d] failed: %s", i, err)
+		}
+		expected := exporter.FormatStr("", markdown)
+		if expected != formatted {
+			t.Fatalf("test case [%d] failed\nexpected\n\t%q\ngot\n\t%q", i, expected, formatted)
+		}
+	}
+
+	if _, err := luteEngine.Mdast2Md("{\"type\":\"paragraph\"}"); nil == err {
+		t.Fatalf("non-root mdast should fail")
+	}
+}
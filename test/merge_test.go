@@ -0,0 +1,56 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+type mergeTest struct {
+	name      string
+	base      string
+	ours      string
+	theirs    string
+	merged    string
+	conflicts string
+}
+
+const mergeDocIAL = "{: id=\"20201111111111-doc0000\" type=\"doc\"}\n"
+
+var mergeTests = []mergeTest{
+
+	{"4", "foo\n{: id=\"a\"}\n\nbar\n{: id=\"b\"}\n", "bar\n{: id=\"b\"}\n\nfoo\n{: id=\"a\"}\n", "foo\n{: id=\"a\"}\n\nbar2\n{: id=\"b\"}\n", "bar2\n{: id=\"b\"}\n\nfoo\n{: id=\"a\"}\n\n\n" + mergeDocIAL, ""},
+	{"3", "foo\n{: id=\"a\"}\n\nbar\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n", "foo\n{: id=\"a\"}\n\nbar2\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n\n<<<<<<< ours\n=======\nbar2\n{: id=\"b\"}\n>>>>>>> theirs\n\n\n" + mergeDocIAL, "b:delete/modify"},
+	{"2", "foo\n{: id=\"a\"}\n\nbar\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n\nbar1\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n\nbar2\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n\n<<<<<<< ours\nbar1\n{: id=\"b\"}\n=======\nbar2\n{: id=\"b\"}\n>>>>>>> theirs\n\n\n" + mergeDocIAL, "b:modify/modify"},
+	{"1", "foo\n{: id=\"a\"}\n\nbar\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n\nnew\n{: id=\"c\"}\n\nbar\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n", "foo\n{: id=\"a\"}\n\nnew\n{: id=\"c\"}\n\n\n" + mergeDocIAL, ""},
+	{"0", "foo\n{: id=\"a\"}\n\nbar\n{: id=\"b\"}\n", "foo1\n{: id=\"a\"}\n\nbar\n{: id=\"b\"}\n", "foo\n{: id=\"a\"}\n\nbar2\n{: id=\"b\"}\n", "foo1\n{: id=\"a\"}\n\nbar2\n{: id=\"b\"}\n\n\n" + mergeDocIAL, ""},
+}
+
+func TestMerge(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	for _, test := range mergeTests {
+		merged, conflicts := luteEngine.Merge(test.base+"\n"+mergeDocIAL, test.ours+"\n"+mergeDocIAL, test.theirs+"\n"+mergeDocIAL)
+		if test.merged != merged {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.merged, merged)
+		}
+		var got []string
+		for _, conflict := range conflicts {
+			got = append(got, conflict.ID+":"+conflict.Reason)
+		}
+		if test.conflicts != strings.Join(got, ",") {
+			t.Fatalf("test case [%s] failed\nexpected conflicts\n\t%q\ngot\n\t%q", test.name, test.conflicts, strings.Join(got, ","))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var outlineTests = []parseTest{
+
+	{"3", "foo\n", "[]"},
+	{"2", "# A\n{: id=\"20201111111111-aaaaaaa\"}\n\n{{{row\n## B\n{: id=\"20201111111111-bbbbbbb\"}\n\nfoo\n{: id=\"20201111111111-ccccccc\"}\n}}}\n{: id=\"20201111111111-ddddddd\"}\n", "[{\"id\":\"20201111111111-aaaaaaa\",\"level\":1,\"text\":\"A\",\"blocks\":[{\"id\":\"20201111111111-ddddddd\",\"type\":\"NodeSuperBlock\"}],\"children\":[{\"id\":\"20201111111111-bbbbbbb\",\"level\":2,\"text\":\"B\",\"blocks\":[{\"id\":\"20201111111111-ccccccc\",\"type\":\"NodeParagraph\"}],\"children\":[]}]}]"},
+	{"1", "> ### 引述 `code`\n\n## A\n", "[{\"id\":\"引述-\",\"level\":3,\"text\":\"引述 \\u003ccode\\u003ecode\\u003c/code\\u003e\",\"blocks\":[],\"children\":[]},{\"id\":\"A\",\"level\":2,\"text\":\"A\",\"blocks\":[],\"children\":[]}]"},
+	{"0", "Setext\n===\n\nfoo\n\n## A\n\n### B\n\nbar\n\n## A\n", "[{\"id\":\"Setext\",\"level\":1,\"text\":\"Setext\",\"blocks\":[{\"type\":\"NodeParagraph\"}],\"children\":[{\"id\":\"A\",\"level\":2,\"text\":\"A\",\"blocks\":[],\"children\":[{\"id\":\"B\",\"level\":3,\"text\":\"B\",\"blocks\":[{\"type\":\"NodeParagraph\"}],\"children\":[]}]},{\"id\":\"A-\",\"level\":2,\"text\":\"A\",\"blocks\":[],\"children\":[]}]}]"},
+}
+
+func TestOutline(t *testing.T) {
+	luteEngine := lute.New()
+	ialEngine := lute.New()
+	ialEngine.SetKramdownIAL(true)
+	ialEngine.SetSuperBlock(true)
+	for _, test := range outlineTests {
+		engine := luteEngine
+		if "2" == test.name {
+			engine = ialEngine
+		}
+		jsonStr := engine.RenderOutline(test.from)
+		if test.to != jsonStr {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, jsonStr, test.from)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+// pandoc-caseN.json 为 pandoc-caseN.md 的 Pandoc JSON AST 期望输出，可以使用 pandoc -f json 进行验证。
+func TestPandoc(t *testing.T) {
+	for i, caseName := range []string{"pandoc-case0", "pandoc-case1"} {
+		luteEngine := lute.New()
+		luteEngine.ParseOptions.BlockRef = true
+		luteEngine.ParseOptions.Tag = true
+		luteEngine.ParseOptions.SuperBlock = true
+		luteEngine.ParseOptions.Mark = true
+		luteEngine.SetKramdownIAL(1 == i)
+
+		markdown, err := ioutil.ReadFile(caseName + ".md")
+		if nil != err {
+			t.Fatalf("read test data failed: %s", err)
+		}
+		golden, err := ioutil.ReadFile(caseName + ".json")
+		if nil != err {
+			t.Fatalf("read test data failed: %s", err)
+		}
+		expected := bytes.Buffer{}
+		if err = json.Compact(&expected, golden); nil != err {
+			t.Fatalf("invalid golden JSON [%s]: %s", caseName, err)
+		}
+
+		got := luteEngine.RenderPandoc(string(markdown))
+		if expected.String() != got {
+			t.Fatalf("test case [%s] writer failed\nexpected\n\t%s\ngot\n\t%s", caseName, expected.String(), got)
+		}
+
+		formatted, err := luteEngine.Pandoc2Md(string(golden))
+		if nil != err {
+			t.Fatalf("test case [%s] reader failed: %s", caseName, err)
+		}
+		if expectedMd := luteEngine.FormatStr(caseName, string(markdown)); expectedMd != formatted {
+			t.Fatalf("test case [%s] reader failed\nexpected\n\t%q\ngot\n\t%q", caseName, expectedMd, formatted)
+		}
+	}
+}
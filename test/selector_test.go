@@ -0,0 +1,79 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/builder"
+	"github.com/sunlightcs/lute/parse"
+)
+
+type selectorTest struct {
+	name     string
+	from     string
+	selector string
+	to       string
+}
+
+var selectorTests = []selectorTest{
+
+	{"9", "# foo\n\n```go\nbar\n```\n\n```\nbaz\n```\n", "codeBlock[lang=go]", "NodeCodeBlock:"},
+	{"8", "* [x] foo\n* [ ] bar\n", "list > listItem[checked=true]", "NodeListItem: foo"},
+	{"7", "foo\n{: id=\"20201111111111-aaaaaaa\" custom-status=\"done\"}\n\nbar\n{: id=\"20201111111111-bbbbbbb\" custom-status=\"todo\"}\n", "[custom-status=done]", "NodeParagraph:foo"},
+	{"6", "foo\n{: id=\"20201111111111-aaaaaaa\"}\n\nbar\n{: id=\"20201111111111-bbbbbbb\"}\n", "[id='20201111111111-bbbbbbb']", "NodeParagraph:bar"},
+	{"5", "> ![foo](/foo.png)\n\n![bar](/bar.png)\n", "blockquote image", "NodeImage:foo"},
+	{"4", "## foo\n{: id=\"20201111111111-aaaaaaa\"}\n\n| a |\n| - |\n{: id=\"20201111111111-bbbbbbb\"}\n\n## bar\n\nbaz\n", "heading[level=2] + table", "NodeTable:a"},
+	{"3", "foo\n\n# bar\n\n## baz\n", "paragraph ~ heading", "NodeHeading:bar|NodeHeading:baz"},
+	{"2", "# foo\n\n| a |\n| - |\n\n## bar\n", "NodeTable, Heading", "NodeHeading:foo|NodeTable:a|NodeHeading:bar"},
+	{"1", "# foo\n\n## bar\n\n### baz\n", "heading[level!=2]", "NodeHeading:foo|NodeHeading:baz"},
+	{"0", "# foo\n\n## bar *baz*\n", "heading[level=2] emphasis", "NodeEmphasis:baz"},
+}
+
+func TestSelector(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	for _, test := range selectorTests {
+		tree := parse.Parse("", []byte(test.from), luteEngine.ParseOptions)
+		nodes, err := ast.Query(tree.Root, test.selector)
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		var got []string
+		for _, n := range nodes {
+			got = append(got, n.Type.String()+":"+n.Text())
+		}
+		if test.to != strings.Join(got, "|") {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\nselector\n\t%q", test.name, test.to, strings.Join(got, "|"), test.selector)
+		}
+	}
+
+	for _, selector := range []string{"", "heading[", "heading[level=2", "foo", "> heading", "heading >", "heading,", "heading[level='2]"} {
+		if _, err := ast.ParseSelector(selector); nil == err {
+			t.Fatalf("selector [%s] should be invalid", selector)
+		}
+	}
+}
+
+func TestSelectorBlankCodeBlockInfo(t *testing.T) {
+	// 信息字符串只有空白时代码块没有语言
+	doc := builder.Document(builder.CodeBlock(" ", "x"), builder.CodeBlock("go ", "y"))
+	nodes, err := ast.Query(doc, "NodeCodeBlock[lang=go]")
+	if nil != err {
+		t.Fatalf("query failed: %s", err)
+	}
+	if 1 != len(nodes) || doc.LastChild != nodes[0] {
+		t.Fatalf("unexpected nodes: %v", nodes)
+	}
+}
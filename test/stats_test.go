@@ -0,0 +1,54 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+)
+
+var statsTests = []parseTest{
+
+	{"4", "{: id=\"20201111111111-aaaaaaa\"}\n", "{\"cjkChars\":0,\"latinWords\":0,\"words\":0,\"paragraphs\":0,\"headings\":0,\"images\":0,\"links\":0,\"codeBlocks\":0,\"tables\":0,\"readingMinutes\":0,\"blocks\":[]}"},
+	{"3", "| 名称 | name |\n| - | - |\n| 苹果 | `apple` |\n", "{\"cjkChars\":4,\"latinWords\":1,\"words\":5,\"paragraphs\":0,\"headings\":0,\"images\":0,\"links\":0,\"codeBlocks\":0,\"tables\":1,\"readingMinutes\":1,\"blocks\":[{\"type\":\"NodeTable\",\"cjkChars\":4,\"latinWords\":1,\"images\":0,\"links\":0}]}"},
+	{"2", "# 标题 Title\n{: id=\"20201111111111-aaaaaaa\"}\n\n```go\nfunc main() {}\n```\n", "{\"cjkChars\":2,\"latinWords\":1,\"words\":3,\"paragraphs\":0,\"headings\":1,\"images\":0,\"links\":0,\"codeBlocks\":1,\"tables\":0,\"readingMinutes\":1,\"blocks\":[{\"id\":\"20201111111111-aaaaaaa\",\"type\":\"NodeHeading\",\"cjkChars\":2,\"latinWords\":1,\"images\":0,\"links\":0},{\"type\":\"NodeCodeBlock\",\"cjkChars\":0,\"latinWords\":0,\"images\":0,\"links\":0}]}"},
+	{"1", "访问 [Lute 引擎](https://github.com/88250/lute) 和 <https://b3log.org> ![图片 alt](/a.png)\n\n* don't well-known foo*bar*\n", "{\"cjkChars\":5,\"latinWords\":4,\"words\":9,\"paragraphs\":2,\"headings\":0,\"images\":1,\"links\":2,\"codeBlocks\":0,\"tables\":0,\"readingMinutes\":1,\"blocks\":[{\"type\":\"NodeParagraph\",\"cjkChars\":5,\"latinWords\":1,\"images\":1,\"links\":2},{\"type\":\"NodeParagraph\",\"cjkChars\":0,\"latinWords\":3,\"images\":0,\"links\":0}]}"},
+	{"0", "中文English混排，共 **七个** 字。", "{\"cjkChars\":8,\"latinWords\":1,\"words\":9,\"paragraphs\":1,\"headings\":0,\"images\":0,\"links\":0,\"codeBlocks\":0,\"tables\":0,\"readingMinutes\":1,\"blocks\":[{\"type\":\"NodeParagraph\",\"cjkChars\":8,\"latinWords\":1,\"images\":0,\"links\":0}]}"},
+}
+
+func TestStats(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	for _, test := range statsTests {
+		stats, err := luteEngine.Stats(test.from)
+		if nil != err {
+			t.Fatalf("test case [%s] failed: %s", test.name, err)
+		}
+		data, _ := json.Marshal(stats)
+		if test.to != string(data) {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%s\ngot\n\t%s\noriginal markdown text\n\t%q", test.name, test.to, data, test.from)
+		}
+	}
+}
+
+func TestStatsReadingTime(t *testing.T) {
+	luteEngine := lute.New()
+	var markdown string
+	for i := 0; i < 301; i++ {
+		markdown += "字"
+	}
+	stats, _ := luteEngine.Stats(markdown)
+	if 2 != stats.ReadingMinutes {
+		t.Fatalf("expected 2 minutes, got %d", stats.ReadingMinutes)
+	}
+}
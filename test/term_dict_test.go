@@ -0,0 +1,141 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/render"
+)
+
+var termDictTests = []parseTest{
+
+	{"3", "visual studio code.\n", "<p>visual studio code.</p>\n"},
+	{"2", "使用visual  STUDIO code和vscode开发node.js项目\n", "<p>使用 Visual Studio Code 和 VS Code 开发 Node.js 项目</p>\n"},
+	{"1", "github 和 mysql\n", "<p>GitHub Inc 和 MySQL</p>\n"},
+	{"0", "visual studio 2019\n", "<p>visual studio 2019</p>\n"},
+}
+
+func TestTermDict(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAutoSpace(true)
+	luteEngine.SetFixTermTypo(true)
+	if err := luteEngine.LoadTerms("terms-case0.txt"); nil != err {
+		t.Fatalf("load terms failed: %s", err)
+	}
+	for _, test := range termDictTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+
+	// 字典只对加载它的引擎生效
+	otherEngine := lute.New()
+	otherEngine.SetFixTermTypo(true)
+	if html := otherEngine.MarkdownStr("", "github vscode\n"); "<p>GitHub vscode</p>\n" != html {
+		t.Fatalf("terms leaked to another engine: %q", html)
+	}
+}
+
+func TestTermDictLoad(t *testing.T) {
+	dict := render.NewTermDict()
+	if err := dict.Load(strings.NewReader("# comment\n\nfoo=Foo\nbar\n")); nil == err || "invalid term [bar] at line 4" != err.Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dict = render.NewTermDict()
+	buf := &strings.Builder{}
+	for i := 0; i < 50000; i++ {
+		buf.WriteString("term" + strconv.Itoa(i) + "=Term" + strconv.Itoa(i) + "\n")
+	}
+	if err := dict.Load(strings.NewReader(buf.String())); nil != err {
+		t.Fatalf("load terms failed: %s", err)
+	}
+	if 50000 != dict.Len() {
+		t.Fatalf("expected 50000 terms, got %d", dict.Len())
+	}
+	if fixed := string(render.FixTerms([]byte("term1 和 term49999"), []*render.TermDict{dict})); "Term1 和 Term49999" != fixed {
+		t.Fatalf("unexpected fixed text: %q", fixed)
+	}
+}
+
+func TestTermTypoLint(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.LintOptions.Rules[lute.LintCJKSpacing] = false
+	if err := luteEngine.LoadTerms("terms-case0.txt"); nil != err {
+		t.Fatalf("load terms failed: %s", err)
+	}
+
+	markdown := "# 使用 vscode\n\n安装 node.js 和\nVisual studio code，参见 github.com\n"
+	expected := "1:6: term-typo: term \"vscode\" should be \"VS Code\"\n3:4: term-typo: term \"node.js\" should be \"Node.js\"\n4:1: term-typo: term \"Visual studio code\" should be \"Visual Studio Code\"\n"
+	diagnostics, err := luteEngine.Lint(markdown)
+	if nil != err {
+		t.Fatalf("lint failed: %s", err)
+	}
+	buf := &strings.Builder{}
+	for _, diagnostic := range diagnostics {
+		if lute.LintTermTypo == diagnostic.Rule {
+			buf.WriteString(diagnostic.String() + "\n")
+		}
+	}
+	if got := buf.String(); expected != got {
+		t.Fatalf("lint failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", expected, got, markdown)
+	}
+
+	fixed := luteEngine.Fix(markdown)
+	if expected = "# 使用 VS Code\n\n安装 Node.js 和\nVisual Studio Code，参见 github.com\n"; expected != fixed {
+		t.Fatalf("fix failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", expected, fixed, markdown)
+	}
+}
+
+func TestTermDictPutTerms(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetFixTermTypo(true)
+	if html := luteEngine.MarkdownStr("", "github lute\n"); "<p>GitHub lute</p>\n" != html {
+		t.Fatalf("unexpected html: %q", html)
+	}
+
+	luteEngine.PutTerms(map[string]string{"lute": "Lute"})
+	if html := luteEngine.MarkdownStr("", "github lute\n"); "<p>GitHub Lute</p>\n" != html {
+		t.Fatalf("put terms failed: %q", html)
+	}
+
+	luteEngine.SetTerms(map[string]string{"vscode": "VS Code"})
+	if html := luteEngine.MarkdownStr("", "github lute vscode\n"); "<p>github lute VS Code</p>\n" != html {
+		t.Fatalf("set terms failed: %q", html)
+	}
+
+	// 直接修改 Terms 也要生效
+	luteEngine.RenderOptions.Terms = map[string]string{"foobar": "FooBar"}
+	if html := luteEngine.MarkdownStr("", "foobar vscode\n"); "<p>FooBar vscode</p>\n" != html {
+		t.Fatalf("assign terms failed: %q", html)
+	}
+	luteEngine.GetTerms()["vscode"] = "VSCode"
+	if html := luteEngine.MarkdownStr("", "foobar vscode\n"); "<p>FooBar VSCode</p>\n" != html {
+		t.Fatalf("modify terms failed: %q", html)
+	}
+
+	// 内置术语字典只构建一次，创建引擎和渲染时不再重新构建
+	luteEngine.SetTerms(render.NewTerms())
+	allocs := testing.AllocsPerRun(10, func() {
+		luteEngine.MarkdownStr("", "github\n")
+	})
+	if 500 < allocs {
+		t.Fatalf("too many allocations per render: %v", allocs)
+	}
+	if allocs = testing.AllocsPerRun(10, func() { lute.New() }); 100 < allocs {
+		t.Fatalf("too many allocations per engine: %v", allocs)
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 var tocTests = []parseTest{
 
+	{"1", "[toc]\n\n# foo\n\n> # bar\n\n* # baz\n", "<div class=\"vditor-toc\" data-block=\"0\" data-type=\"toc-block\" contenteditable=\"false\"><ul><li><span data-target-id=\"foo\">foo</span></li></ul></div>\n<h1 id=\"foo\">foo</h1>\n<blockquote>\n<h1 id=\"bar\">bar</h1>\n</blockquote>\n<ul>\n<li>\n<h1 id=\"baz\">baz</h1>\n</li>\n</ul>\n"},
 	{"0", "[toc]\n\n# 1\n\n## 1.1\n\n# 2\n", "<div class=\"vditor-toc\" data-block=\"0\" data-type=\"toc-block\" contenteditable=\"false\"><ul><li><span data-target-id=\"1\">1</span><ul><li><span data-target-id=\"1-1\">1.1</span></li></ul></li><li><span data-target-id=\"2\">2</span></li></ul></div>\n<h1 id=\"1\">1</h1>\n<h2 id=\"1-1\">1.1</h2>\n<h1 id=\"2\">2</h1>\n"},
 }
 
@@ -33,3 +34,32 @@ func TestToC(t *testing.T) {
 		}
 	}
 }
+
+var tocOptionsTests = []parseTest{
+
+	{"3", "# 1\n\n## 1.1\n{: toc-exclude=\"true\"}\n\n### 1.1.1\n", "<ul><li><span data-target-id=\"1\">1</span><ul><li><span data-target-id=\"1-1-1\">1.1.1</span></li></ul></li></ul>"},
+	{"2", "# 1\n\n## 1.1\n\n## 1.2\n\n# 2\n", "<div class=\"vditor-toc__title\">目录 &lt;1&gt;</div><ol><li><span data-target-id=\"1\">1 1</span><ol><li><span data-target-id=\"1-1\">1.1 1.1</span></li><li><span data-target-id=\"1-2\">1.2 1.2</span></li></ol></li><li><span data-target-id=\"2\">2 2</span></li></ol>"},
+	{"1", "# 1\n\n## 1.1\n\n### 1.1.1\n\n#### 1.1.1.1\n\n## 1.2\n", "<ul><li><span data-target-id=\"1-1\">1.1</span><ul><li><span data-target-id=\"1-1-1\">1.1.1</span></li></ul></li><li><span data-target-id=\"1-2\">1.2</span></li></ul>"},
+	{"0", "foo\n", ""},
+}
+
+func TestToCOptions(t *testing.T) {
+	for _, test := range tocOptionsTests {
+		luteEngine := lute.New()
+		luteEngine.SetKramdownIAL(true)
+		switch test.name {
+		case "1":
+			luteEngine.SetToCMinLevel(2)
+			luteEngine.SetToCMaxLevel(3)
+		case "2":
+			luteEngine.SetToCOrdered(true)
+			luteEngine.SetToCNumbered(true)
+			luteEngine.SetToCTitle("目录 <1>")
+		}
+
+		html := luteEngine.ToC(test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
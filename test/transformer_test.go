@@ -0,0 +1,77 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+)
+
+func TestTransformer(t *testing.T) {
+	luteEngine := lute.New()
+	// 改写链接地址
+	luteEngine.AddTransformer(func(tree *parse.Tree) error {
+		ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if entering && ast.NodeLinkDest == n.Type {
+				n.Tokens = []byte(strings.Replace(n.TokensStr(), "http://", "https://", 1))
+			}
+			return ast.WalkContinue
+		})
+		return nil
+	})
+	// 移除私有段落，依赖前一个转换器的结果
+	luteEngine.AddTransformer(func(tree *parse.Tree) error {
+		var private []*ast.Node
+		for n := tree.Root.FirstChild; nil != n; n = n.Next {
+			if strings.HasPrefix(n.Text(), "private") {
+				private = append(private, n)
+			}
+		}
+		for _, n := range private {
+			n.Unlink()
+		}
+		return nil
+	})
+
+	markdown := "[foo](http://b3log.org)\n\nprivate bar\n"
+	if html := luteEngine.MarkdownStr("", markdown); "<p><a href=\"https://b3log.org\">foo</a></p>\n" != html {
+		t.Fatalf("Markdown failed, got %q", html)
+	}
+	if formatted := luteEngine.FormatStr("", markdown); "[foo](https://b3log.org)\n" != formatted {
+		t.Fatalf("Format failed, got %q", formatted)
+	}
+	for _, output := range []string{luteEngine.Md2VditorDOM(markdown), luteEngine.Md2VditorIRDOM(markdown), luteEngine.Md2VditorIRBlockDOM(markdown), luteEngine.Md2VditorSVDOM(markdown), luteEngine.RenderJSON(markdown)} {
+		if strings.Contains(output, "private") || !strings.Contains(output, "https://b3log.org") {
+			t.Fatalf("Md2Vditor* or RenderJSON failed, got %q", output)
+		}
+	}
+
+	luteEngine.AddTransformer(func(tree *parse.Tree) error {
+		return errors.New("foo")
+	})
+	if html := luteEngine.MarkdownStr("", markdown); "transformer [2] failed: foo" != html {
+		t.Fatalf("error should be reported, got %q", html)
+	}
+
+	luteEngine.Transformers = []lute.Transformer{func(tree *parse.Tree) error {
+		tree.Root.FirstChild.FirstChild.FirstChild.FirstChild.Type = ast.NodeText // 访问不存在的节点触发 panic
+		return nil
+	}}
+	tree := parse.Parse("", []byte("foo"), luteEngine.ParseOptions)
+	if err := luteEngine.Transform(tree); nil == err || !strings.HasPrefix(err.Error(), "transformer [0] failed: PANIC RECOVERED") {
+		t.Fatalf("panic should be recovered, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+)
+
+var treeJSONTests = []string{
+	"foo[^1] bar[^1]\n\n[^1]: baz\n",
+	"# 标题 {#id}\n\n* [ ] foo\n* [x] bar\n\n1) baz\n{: id=\"20201111111111-aaaaaaa\" custom-foo=\"bar\"}\n",
+	"| a | b |\n| :- | -: |\n| ~~c~~ | ==d== |\n\n$$\nx^2\n$$\n\n```go\nfunc() {}\n```\n",
+	"((20201105103725-aaaaaaa \"foo\"))\n\n{{{\nfoo\n\n#tag#\n}}}\n",
+}
+
+func TestTreeJSON(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.ParseOptions.BlockRef = true
+	luteEngine.ParseOptions.Mark = true
+	luteEngine.ParseOptions.Tag = true
+	luteEngine.ParseOptions.SuperBlock = true
+
+	cases := treeJSONTests
+	for i := 0; i < 8; i++ {
+		data, err := ioutil.ReadFile("format-case" + strconv.Itoa(i) + ".md")
+		if nil != err {
+			t.Fatalf("read test data failed: %s", err)
+		}
+		cases = append(cases, string(data))
+	}
+
+	for i, markdown := range cases {
+		tree := parse.Parse("", []byte(markdown), luteEngine.ParseOptions)
+		data, err := json.Marshal(tree)
+		if nil != err {
+			t.Fatalf("test case [%d] marshal failed: %s", i, err)
+		}
+		loaded, err := parse.ParseJSON(data)
+		if nil != err {
+			t.Fatalf("test case [%d] unmarshal failed: %s", i, err)
+		}
+
+		ast.Walk(loaded.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if entering && nil != n.FirstChild && (n != n.FirstChild.Parent || nil != n.FirstChild.Previous) {
+				t.Fatalf("test case [%d] broken node links", i)
+			}
+			return ast.WalkContinue
+		})
+
+		// 源码行号也要保留，按块最小化格式化依赖行号
+		lines, loadedLines := sourceLines(tree.Root), sourceLines(loaded.Root)
+		if lines != loadedLines {
+			t.Fatalf("test case [%d] source line mismatch\nexpected\n\t%q\ngot\n\t%q", i, lines, loadedLines)
+		}
+		if expected, got := string(luteEngine.FormatTreeMinimal(tree, []byte(markdown))), string(luteEngine.FormatTreeMinimal(loaded, []byte(markdown))); expected != got {
+			t.Fatalf("test case [%d] minimal format mismatch\nexpected\n\t%q\ngot\n\t%q", i, expected, got)
+		}
+
+		expected := luteEngine.Tree2HTML(tree, luteEngine.RenderOptions)
+		got := luteEngine.Tree2HTML(loaded, luteEngine.RenderOptions)
+		if expected != got {
+			t.Fatalf("test case [%d] HTML mismatch\nexpected\n\t%q\ngot\n\t%q", i, expected, got)
+		}
+		expected = string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render())
+		got = string(render.NewFormatRenderer(loaded, luteEngine.RenderOptions).Render())
+		if expected != got {
+			t.Fatalf("test case [%d] Markdown mismatch\nexpected\n\t%q\ngot\n\t%q", i, expected, got)
+		}
+	}
+
+	if _, err := parse.ParseJSON([]byte("{\"Schema\":99,\"Root\":{\"Type\":\"NodeDocument\"}}")); nil == err {
+		t.Fatalf("unsupported schema version should fail")
+	}
+	if _, err := parse.ParseJSON([]byte("{\"Schema\":1,\"Root\":{\"Type\":\"NodeFoo\"}}")); nil == err {
+		t.Fatalf("unknown node type should fail")
+	}
+}
+
+func sourceLines(root *ast.Node) string {
+	var ret []byte
+	ast.Walk(root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			ret = strconv.AppendInt(ret, int64(n.SourceLine), 10)
+			ret = append(ret, ' ')
+		}
+		return ast.WalkContinue
+	})
+	return string(ret)
+}
@@ -0,0 +1,85 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunlightcs/lute"
+	"github.com/sunlightcs/lute/ast"
+	"github.com/sunlightcs/lute/builder"
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/render"
+)
+
+type treeValidateTest struct {
+	name       string
+	doc        func() *ast.Node
+	violations string
+	repaired   string
+}
+
+var treeValidateTests = []treeValidateTest{
+
+	{"4", func() *ast.Node {
+		strong := builder.Strong(builder.Text("foo"))
+		strong.LastChild.Unlink()
+		return builder.Document(builder.Paragraph(strong, builder.Text(" bar")))
+	}, "NodeDocument/NodeParagraph[0]/NodeStrong[0]: missing close marker NodeStrongA6kCloseMarker", "**foo** bar\n"},
+	{"3", func() *ast.Node {
+		return builder.Document(builder.Paragraph(builder.Text("foo"), builder.CodeBlock("", "bar")))
+	}, "NodeDocument/NodeParagraph[0]/NodeCodeBlock[1]: block NodeCodeBlock inside NodeParagraph", "foo\n\n```\nbar\n```\n"},
+	{"2", func() *ast.Node {
+		return builder.Document(builder.Cell(builder.Text("a")), builder.Cell(builder.Text("b")))
+	}, "NodeDocument/NodeTableCell[0]: table cell outside table row\nNodeDocument/NodeTableCell[1]: table cell outside table row", "| a | b |\n| - | - |\n"},
+	{"1", func() *ast.Node {
+		return builder.Document(builder.Text("foo"), builder.Paragraph(builder.Text("a"), builder.Emphasis(), builder.Text("b")))
+	}, "NodeDocument/NodeText[0]: inline NodeText outside leaf block\nNodeDocument/NodeParagraph[1]/NodeEmphasis[1]: empty NodeEmphasis", "foo\n\nab\n"},
+	{"0", func() *ast.Node {
+		return builder.Document(builder.Item(builder.Paragraph(builder.Text("foo"))), builder.Item(builder.Paragraph(builder.Text("bar"))))
+	}, "NodeDocument/NodeListItem[0]: list item outside list\nNodeDocument/NodeListItem[1]: list item outside list", "* foo\n* bar\n"},
+}
+
+func TestTreeValidate(t *testing.T) {
+	luteEngine := lute.New()
+	for _, test := range treeValidateTests {
+		tree := builder.Tree(test.doc(), luteEngine.ParseOptions)
+		var violations []string
+		for _, v := range tree.Validate() {
+			violations = append(violations, v.String())
+		}
+		if got := strings.Join(violations, "\n"); test.violations != got {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.violations, got)
+		}
+
+		if remains := tree.Repair(); 0 < len(remains) {
+			t.Fatalf("test case [%s] failed: violations remain after repair [%s]", test.name, remains[0])
+		}
+		if 0 == len(test.repaired) {
+			continue
+		}
+		formatted := string(render.NewFormatRenderer(tree, luteEngine.RenderOptions).Render())
+		if test.repaired != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.repaired, formatted)
+		}
+	}
+}
+
+func TestTreeValidateParsed(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetSuperBlock(true)
+	tree := parse.Parse("", []byte("# foo\n\n* bar\n  > baz\n\n| a |\n| - |\n| b |\n\n{{{row\nfoo\n}}}\n"), luteEngine.ParseOptions)
+	if violations := tree.Validate(); 0 < len(violations) {
+		t.Fatalf("unexpected violation [%s]", violations[0])
+	}
+}
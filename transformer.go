@@ -0,0 +1,51 @@
+// Lute - 一款对中文语境优化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/sunlightcs/lute/parse"
+	"github.com/sunlightcs/lute/util"
+)
+
+// Transformer 描述了语法树转换器，在解析之后、渲染之前修改语法树，比如改写链接、插入目录或者移除私有内容。
+type Transformer func(tree *parse.Tree) error
+
+// AddTransformer 在转换器列表末尾添加语法树转换器 transformer。
+func (lute *Lute) AddTransformer(transformer Transformer) {
+	lute.Transformers = append(lute.Transformers, transformer)
+}
+
+// Transform 按顺序使用转换器列表中的转换器转换语法树 tree。
+//
+// 转换器返回错误或者发生 panic 时停止转换并返回错误。Markdown、Format 等不返回错误的入口会将错误信息作为输出结果。
+func (lute *Lute) Transform(tree *parse.Tree) (err error) {
+	for i, transformer := range lute.Transformers {
+		if err = transform(transformer, tree); nil != err {
+			return errors.New("transformer [" + strconv.Itoa(i) + "] failed: " + err.Error())
+		}
+	}
+	return
+}
+
+func transform(transformer Transformer, tree *parse.Tree) (err error) {
+	defer util.RecoverPanic(&err)
+	return transformer(tree)
+}
+
+// parse 解析 markdown 并使用转换器列表转换生成的语法树。
+func (lute *Lute) parse(name string, markdown []byte) (tree *parse.Tree, err error) {
+	tree = parse.Parse(name, markdown, lute.ParseOptions)
+	err = lute.Transform(tree)
+	return
+}
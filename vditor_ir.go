@@ -63,7 +63,11 @@ func (lute *Lute) VditorIRDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorIRDOM 将 markdown 转换为 Vditor Instant-Rendering DOM，用于从源码模式切换至即时渲染模式。
 func (lute *Lute) Md2VditorIRDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		vHTML = err.Error()
+		return
+	}
 	renderer := render.NewVditorIRRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorIRDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -49,6 +49,7 @@ func (lute *Lute) HTML2VditorIRBlockDOM(sHTML string) (vHTML string) {
 
 	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
 	renderer := render.NewVditorIRBlockRenderer(tree, lute.RenderOptions)
+	renderer.BlockResolver = lute.BlockResolver
 	for nodeType, rendererFunc := range lute.HTML2VditorIRBlockDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
 	}
@@ -66,7 +67,11 @@ func (lute *Lute) VditorIRBlockDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorIRBlockDOM 将 markdown 转换为 Vditor Instant-Rendering Block DOM，用于从源码模式切换至即时渲染块模式。
 func (lute *Lute) Md2VditorIRBlockDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		vHTML = err.Error()
+		return
+	}
 	renderer := render.NewVditorIRBlockRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorIRBlockDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -137,6 +142,7 @@ func (lute *Lute) VditorIRBlockDOM2TextLen(htmlStr string) int {
 
 func (lute *Lute) Tree2VditorIRBlockDOM(tree *parse.Tree, options *render.Options) (vHTML string) {
 	renderer := render.NewVditorIRBlockRenderer(tree, options)
+	renderer.BlockResolver = lute.BlockResolver
 	output := renderer.Render()
 	vHTML = string(output)
 	return
@@ -54,7 +54,11 @@ func (lute *Lute) HTML2VditorSVDOM(sHTML string) (vHTML string) {
 
 // Md2VditorSVDOM 将 markdown 转换为 Vditor Split-View DOM，用于从源码模式切换至分屏预览模式。
 func (lute *Lute) Md2VditorSVDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		vHTML = err.Error()
+		return
+	}
 	renderer := render.NewVditorSVRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorSVDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
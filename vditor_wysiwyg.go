@@ -67,7 +67,11 @@ func (lute *Lute) VditorDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorDOM 将 markdown 转换为 Vditor DOM，用于从源码模式切换至所见即所得模式。
 func (lute *Lute) Md2VditorDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree, err := lute.parse("", []byte(markdown))
+	if nil != err {
+		vHTML = err.Error()
+		return
+	}
 	renderer := render.NewVditorRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc